@@ -0,0 +1,16 @@
+// Command oi is the operator CLI for the ontogenetic-intelligence mini-OS.
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/Uiota/ontogenetic-intelligence/internal/cli"
+)
+
+func main() {
+	if err := cli.NewRootCmd().Execute(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
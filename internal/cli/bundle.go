@@ -0,0 +1,191 @@
+package cli
+
+import (
+	"encoding/hex"
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"gitlab.com/yawning/secp256k1-voi/secec"
+
+	"github.com/Uiota/ontogenetic-intelligence/pkg/airgap/bundle"
+)
+
+func newBundleCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "bundle",
+		Short: "Create, verify, and apply signed air-gap update bundles",
+	}
+	cmd.AddCommand(newBundleKeygenCmd())
+	cmd.AddCommand(newBundlePubkeyCmd())
+	cmd.AddCommand(newBundleCreateCmd())
+	cmd.AddCommand(newBundleVerifyCmd())
+	cmd.AddCommand(newBundleApplyCmd())
+	return cmd
+}
+
+func newBundleKeygenCmd() *cobra.Command {
+	var keyIDFlag, outPath string
+	cmd := &cobra.Command{
+		Use:   "keygen",
+		Short: "Generate and persist a new issuer signing key",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			kp, err := bundle.GenerateKeyPair(keyIDFlag)
+			if err != nil {
+				return err
+			}
+			if err := bundle.SaveKeyPair(kp, outPath); err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "generated key %q at %s\npublic key: %s\n", keyIDFlag, outPath, kp.PublicKeyHex())
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&keyIDFlag, "key-id", "", "issuer key id (required)")
+	cmd.Flags().StringVar(&outPath, "out", "issuer-key.json", "path to write the persisted key")
+	cmd.MarkFlagRequired("key-id")
+	return cmd
+}
+
+func newBundlePubkeyCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "pubkey <key-file>",
+		Short: "Print the public key for a key generated by `bundle keygen`",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			kp, err := bundle.LoadKeyPair(args[0])
+			if err != nil {
+				return err
+			}
+			fmt.Fprintln(cmd.OutOrStdout(), kp.PublicKeyHex())
+			return nil
+		},
+	}
+	return cmd
+}
+
+func newBundleCreateCmd() *cobra.Command {
+	var (
+		outPath  string
+		version  uint64
+		prevHash string
+		keyPath  string
+		rotateTo string
+	)
+	cmd := &cobra.Command{
+		Use:   "create <payload-dir>",
+		Short: "Build and sign a new update bundle from a payload directory",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			kp, err := bundle.LoadKeyPair(keyPath)
+			if err != nil {
+				return err
+			}
+
+			var rotation *bundle.KeyTransition
+			if rotateTo != "" {
+				newKP, err := bundle.LoadKeyPair(rotateTo)
+				if err != nil {
+					return err
+				}
+				rotation, err = bundle.SignKeyTransition(kp, newKP.KeyID, newKP.PrivateKey.PublicKey())
+				if err != nil {
+					return err
+				}
+			}
+
+			hash, err := bundle.Create(args[0], outPath, version, prevHash, kp, rotation)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "created %s (hash=%s)\n", outPath, hash)
+			if rotation != nil {
+				fmt.Fprintf(cmd.OutOrStdout(), "embedded key rotation to %q; `bundle verify` reports its public key once the countersignature checks out\n", rotation.NewKeyID)
+			}
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&outPath, "out", "bundle.tar", "output bundle path")
+	cmd.Flags().Uint64Var(&version, "version", 1, "bundle version (monotonic)")
+	cmd.Flags().StringVar(&prevHash, "prev-hash", "", "hash of the bundle this one supersedes (empty for the first bundle)")
+	cmd.Flags().StringVar(&keyPath, "key", "", "path to an issuer key generated by `bundle keygen` (required)")
+	cmd.Flags().StringVar(&rotateTo, "rotate-to", "", "path to a new issuer key to hand off to, countersigned by --key")
+	cmd.MarkFlagRequired("key")
+	return cmd
+}
+
+func newBundleVerifyCmd() *cobra.Command {
+	var pubKeyHex, storePath string
+	cmd := &cobra.Command{
+		Use:   "verify <bundle.tar>",
+		Short: "Verify a bundle's signature and its place in the update chain",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			pub, err := decodePublicKey(pubKeyHex)
+			if err != nil {
+				return err
+			}
+			store, err := bundle.OpenStore(storePath)
+			if err != nil {
+				return err
+			}
+			defer store.Close()
+
+			status := statusFunc(cmd, "bundle verify")
+			status("verifying signature and chain")
+			result, err := bundle.Verify(args[0], pub, store)
+			if err != nil {
+				return err
+			}
+			status(fmt.Sprintf("ok: version=%d hash=%s", result.Manifest.Version, result.Hash))
+			if result.NewIssuerPubKey != nil {
+				status(fmt.Sprintf("key rotation verified: issuer key is handing off to %q (pubkey=%s)", result.Manifest.KeyRotation.NewKeyID, hex.EncodeToString(result.NewIssuerPubKey.Bytes())))
+			}
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&pubKeyHex, "issuer-pubkey", "", "hex-encoded issuer public key (required)")
+	cmd.Flags().StringVar(&storePath, "store", "applied-bundles.db", "path to the last-applied bundle ledger")
+	cmd.MarkFlagRequired("issuer-pubkey")
+	return cmd
+}
+
+func newBundleApplyCmd() *cobra.Command {
+	var pubKeyHex, storePath, destDir string
+	cmd := &cobra.Command{
+		Use:   "apply <bundle.tar>",
+		Short: "Verify and apply a bundle, advancing the last-applied pointer",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			pub, err := decodePublicKey(pubKeyHex)
+			if err != nil {
+				return err
+			}
+			store, err := bundle.OpenStore(storePath)
+			if err != nil {
+				return err
+			}
+			defer store.Close()
+
+			status := statusFunc(cmd, "bundle apply")
+			status("verifying and extracting payload")
+			if err := bundle.Apply(args[0], destDir, pub, store); err != nil {
+				return err
+			}
+			status(fmt.Sprintf("applied %s", args[0]))
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&pubKeyHex, "issuer-pubkey", "", "hex-encoded issuer public key (required)")
+	cmd.Flags().StringVar(&storePath, "store", "applied-bundles.db", "path to the last-applied bundle ledger")
+	cmd.Flags().StringVar(&destDir, "dest", ".", "directory to extract payload files into")
+	cmd.MarkFlagRequired("issuer-pubkey")
+	return cmd
+}
+
+func decodePublicKey(hexKey string) (*secec.PublicKey, error) {
+	raw, err := hex.DecodeString(hexKey)
+	if err != nil {
+		return nil, fmt.Errorf("decoding issuer public key: %w", err)
+	}
+	return secec.NewPublicKey(raw)
+}
@@ -0,0 +1,78 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/Uiota/ontogenetic-intelligence/pkg/policy"
+)
+
+func newPolicyCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "policy",
+		Short: "Evaluate zero-trust capability policy",
+	}
+	cmd.AddCommand(newPolicyTestCmd())
+	return cmd
+}
+
+func newPolicyTestCmd() *cobra.Command {
+	var fixturesPath string
+	cmd := &cobra.Command{
+		Use:   "test <rules.yaml>",
+		Short: "Dry-run a rule bundle against a JSON fixture set",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ruleBytes, err := os.ReadFile(args[0])
+			if err != nil {
+				return err
+			}
+			rules, err := policy.LoadRules(ruleBytes)
+			if err != nil {
+				return err
+			}
+
+			fixtureBytes, err := os.ReadFile(fixturesPath)
+			if err != nil {
+				return err
+			}
+			fixtures, err := policy.LoadFixtures(fixtureBytes)
+			if err != nil {
+				return err
+			}
+
+			reporter := statusFunc(cmd, "policy compile")
+			reporter(fmt.Sprintf("compiling %d rule(s)", len(rules)))
+			results, err := policy.DryRun(cmd.Context(), rules, fixtures)
+			if err != nil {
+				return err
+			}
+			reporter(fmt.Sprintf("evaluated %d fixture case(s)", len(results)))
+
+			out := cmd.OutOrStdout()
+			for _, res := range results {
+				status := "DENY"
+				if res.Decision.Allowed {
+					status = "ALLOW"
+				}
+				fmt.Fprintf(out, "case %q: %s grants=%v\n", res.Fixture.Name, status, res.Decision.Grants)
+				for _, ro := range res.Rules {
+					switch {
+					case ro.Err != nil:
+						fmt.Fprintf(out, "  - rule %q: ERROR %v\n", ro.Rule, ro.Err)
+					case ro.Matched:
+						fmt.Fprintf(out, "  - rule %q: HIT\n", ro.Rule)
+					default:
+						fmt.Fprintf(out, "  - rule %q: MISS\n", ro.Rule)
+					}
+				}
+			}
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&fixturesPath, "fixtures", "", "JSON fixture set to evaluate (required)")
+	cmd.MarkFlagRequired("fixtures")
+	return cmd
+}
@@ -0,0 +1,58 @@
+// Package cli wires the cobra command tree for the oi operator CLI.
+package cli
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/Uiota/ontogenetic-intelligence/pkg/tui"
+)
+
+// tuiEnabled is set by the root --tui flag; subcommands that report
+// progress check it to decide between a live dashboard frame and plain
+// log lines.
+var tuiEnabled bool
+
+// NewRootCmd builds the root "oi" command and attaches all subsystem
+// subcommands to it.
+func NewRootCmd() *cobra.Command {
+	root := &cobra.Command{
+		Use:   "oi",
+		Short: "Operator CLI for the ontogenetic-intelligence mini-OS",
+	}
+	root.PersistentFlags().BoolVar(&tuiEnabled, "tui", false, "render live progress frames instead of plain log lines")
+
+	root.AddCommand(newVaultCmd())
+	root.AddCommand(newPolicyCmd())
+	root.AddCommand(newBundleCmd())
+	root.AddCommand(newSnapCmd())
+
+	return root
+}
+
+// progressFunc returns a progress callback for long-running subsystem
+// calls: a live dashboard frame when --tui was passed (and stdout is a
+// terminal; tui.Frame degrades to plain lines itself otherwise), or nil
+// so the caller skips progress reporting entirely.
+func progressFunc(title string) func(cur, total int) {
+	if !tuiEnabled {
+		return nil
+	}
+	frame := tui.NewFrame(title)
+	return frame.Progress
+}
+
+// statusFunc returns a single-line status reporter for commands that
+// don't have granular progress to report, only a handful of coarse
+// steps (e.g. "verifying signature", "checking chain"). With --tui it
+// updates a dashboard frame in place; otherwise it prints each status
+// as its own line.
+func statusFunc(cmd *cobra.Command, title string) func(string) {
+	if !tuiEnabled {
+		out := cmd.OutOrStdout()
+		return func(msg string) { fmt.Fprintln(out, msg) }
+	}
+	frame := tui.NewFrame(title)
+	return func(msg string) { frame.Set([]string{msg}) }
+}
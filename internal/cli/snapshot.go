@@ -0,0 +1,110 @@
+package cli
+
+import (
+	"encoding/base64"
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/Uiota/ontogenetic-intelligence/pkg/snapshot"
+)
+
+func newSnapCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "snap",
+		Short: "Create and restore content-addressable encrypted snapshots",
+	}
+	cmd.AddCommand(newSnapCreateCmd())
+	cmd.AddCommand(newSnapRestoreCmd())
+	cmd.AddCommand(newSnapGCCmd())
+	return cmd
+}
+
+func snapMasterKey(b64 string) ([]byte, error) {
+	return base64.StdEncoding.DecodeString(b64)
+}
+
+func newSnapCreateCmd() *cobra.Command {
+	var repoDir, masterB64 string
+	cmd := &cobra.Command{
+		Use:   "create <state-dir>",
+		Short: "Snapshot a directory into the chunk repository",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			master, err := snapMasterKey(masterB64)
+			if err != nil {
+				return err
+			}
+			backend, err := snapshot.NewLocalBackend(repoDir)
+			if err != nil {
+				return err
+			}
+			rootID, err := snapshot.Create(args[0], backend, master, progressFunc("snapshot create"))
+			if err != nil {
+				return err
+			}
+			fmt.Fprintln(cmd.OutOrStdout(), rootID)
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&repoDir, "repo", "snapshots", "local chunk repository directory")
+	cmd.Flags().StringVar(&masterB64, "master-key", "", "base64-encoded master key (required)")
+	cmd.MarkFlagRequired("master-key")
+	return cmd
+}
+
+func newSnapRestoreCmd() *cobra.Command {
+	var repoDir, masterB64 string
+	cmd := &cobra.Command{
+		Use:   "restore <root-id> <dest-dir>",
+		Short: "Restore a snapshot by its root id into a directory",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			master, err := snapMasterKey(masterB64)
+			if err != nil {
+				return err
+			}
+			backend, err := snapshot.NewLocalBackend(repoDir)
+			if err != nil {
+				return err
+			}
+			if err := snapshot.Restore(args[0], args[1], backend, master); err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "restored %s into %s\n", args[0], args[1])
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&repoDir, "repo", "snapshots", "local chunk repository directory")
+	cmd.Flags().StringVar(&masterB64, "master-key", "", "base64-encoded master key (required)")
+	cmd.MarkFlagRequired("master-key")
+	return cmd
+}
+
+func newSnapGCCmd() *cobra.Command {
+	var repoDir string
+	var keep []string
+	cmd := &cobra.Command{
+		Use:   "gc",
+		Short: "Sweep chunks unreferenced by any retained snapshot root",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			backend, err := snapshot.NewLocalBackend(repoDir)
+			if err != nil {
+				return err
+			}
+			removed, err := snapshot.GC(backend, keep)
+			if err != nil {
+				return err
+			}
+			out := cmd.OutOrStdout()
+			fmt.Fprintf(out, "removed %d unreferenced chunks\n", len(removed))
+			for _, id := range removed {
+				fmt.Fprintln(out, "  -", id)
+			}
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&repoDir, "repo", "snapshots", "local chunk repository directory")
+	cmd.Flags().StringSliceVar(&keep, "keep", nil, "root ids to retain (repeatable)")
+	return cmd
+}
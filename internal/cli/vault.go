@@ -0,0 +1,80 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/Uiota/ontogenetic-intelligence/pkg/vault"
+)
+
+func newVaultCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "vault",
+		Short: "Manage encrypted-at-rest SQLite vaults",
+	}
+
+	cmd.AddCommand(newVaultInitCmd())
+	cmd.AddCommand(newVaultRekeyCmd())
+	cmd.AddCommand(newVaultVerifyCmd())
+	return cmd
+}
+
+func newVaultInitCmd() *cobra.Command {
+	var passphrase string
+	cmd := &cobra.Command{
+		Use:   "init <path>",
+		Short: "Create a new empty encrypted vault",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := vault.Init(args[0], passphrase); err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "initialized vault %s\n", args[0])
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&passphrase, "passphrase", "", "vault passphrase (required)")
+	cmd.MarkFlagRequired("passphrase")
+	return cmd
+}
+
+func newVaultRekeyCmd() *cobra.Command {
+	var oldPassphrase, newPassphrase string
+	cmd := &cobra.Command{
+		Use:   "rekey <path>",
+		Short: "Re-encrypt a vault under a new passphrase",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := vault.Rekey(args[0], oldPassphrase, newPassphrase, progressFunc("vault rekey")); err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "rekeyed vault %s\n", args[0])
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&oldPassphrase, "passphrase", "", "current vault passphrase (required)")
+	cmd.Flags().StringVar(&newPassphrase, "new-passphrase", "", "new vault passphrase (required)")
+	cmd.MarkFlagRequired("passphrase")
+	cmd.MarkFlagRequired("new-passphrase")
+	return cmd
+}
+
+func newVaultVerifyCmd() *cobra.Command {
+	var passphrase string
+	cmd := &cobra.Command{
+		Use:   "verify <path>",
+		Short: "Check that a passphrase unlocks a vault without opening it",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := vault.Verify(args[0], passphrase); err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "ok: %s unlocks %s\n", "passphrase", args[0])
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&passphrase, "passphrase", "", "vault passphrase (required)")
+	cmd.MarkFlagRequired("passphrase")
+	return cmd
+}
@@ -0,0 +1,273 @@
+// Package bundle defines a self-describing offline update package for
+// air-gapped nodes: a tarball containing a MANIFEST.yaml (payload file
+// digests, a monotonic version, and the previous bundle's hash forming
+// a chain) and a detached secp256k1 signature over a TupleHash-derived,
+// domain-separated digest of the manifest and payload. Verification
+// enforces the previous-hash chain against a locally persisted
+// "last applied" pointer, so a node can never be rolled back or forked
+// onto a different update history. A manifest may also embed a
+// KeyTransition handing off trust to a new issuer key, countersigned by
+// the outgoing one (see SignKeyTransition).
+package bundle
+
+import (
+	"archive/tar"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"gitlab.com/yawning/secp256k1-voi/secec"
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	// ErrInvalidSignature is returned by Verify when the detached
+	// signature does not validate against the trusted public key.
+	ErrInvalidSignature = errors.New("bundle: invalid signature")
+	// ErrChainMismatch is returned by Verify when the manifest's
+	// prev_hash does not match the locally persisted last-applied
+	// bundle, indicating a rollback or fork attempt.
+	ErrChainMismatch = errors.New("bundle: prev_hash does not match last applied bundle (rollback or fork attempt)")
+	// ErrPayloadTampered is returned when a payload file's digest does
+	// not match the one recorded in the manifest.
+	ErrPayloadTampered = errors.New("bundle: payload file digest does not match manifest")
+)
+
+const (
+	manifestName  = "MANIFEST.yaml"
+	signatureName = "MANIFEST.sig"
+)
+
+// VerifyResult is the manifest and chain hash of a bundle that passed
+// signature and chain verification.
+type VerifyResult struct {
+	Manifest *Manifest
+	Hash     string
+	// NewIssuerPubKey is set when the manifest carries a KeyRotation
+	// whose countersignature checked out against pub: the key the issuer
+	// is handing off to, which callers should trust for every bundle
+	// after this one.
+	NewIssuerPubKey *secec.PublicKey
+}
+
+// Create builds a signed bundle tarball at outPath from every regular
+// file under dir, chaining it to prevHash (the Hash of the bundle this
+// one supersedes; empty for the very first bundle an issuer creates).
+// rotation, if non-nil, embeds a signed handoff to a new issuer key (see
+// SignKeyTransition) whose countersignature Verify checks and whose new
+// key it reports in VerifyResult.NewIssuerPubKey; neither Verify nor
+// Apply records that key. Pass nil for an ordinary bundle that doesn't
+// rotate keys.
+// It returns the new bundle's chain hash.
+func Create(dir, outPath string, version uint64, prevHash string, kp *KeyPair, rotation *KeyTransition) (string, error) {
+	var relPaths []string
+	if err := filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		rel, err := filepath.Rel(dir, p)
+		if err != nil {
+			return err
+		}
+		relPaths = append(relPaths, rel)
+		return nil
+	}); err != nil {
+		return "", fmt.Errorf("bundle: walking %s: %w", dir, err)
+	}
+	sort.Strings(relPaths)
+
+	files := make([]FileEntry, 0, len(relPaths))
+	digests := make([][]byte, 0, len(relPaths))
+	for _, rel := range relPaths {
+		data, err := os.ReadFile(filepath.Join(dir, rel))
+		if err != nil {
+			return "", err
+		}
+		sum := sha256.Sum256(data)
+		files = append(files, FileEntry{Path: rel, SHA256: hex.EncodeToString(sum[:]), Size: int64(len(data))})
+		digests = append(digests, sum[:])
+	}
+
+	manifest := Manifest{
+		Version:     version,
+		PrevHash:    prevHash,
+		IssuerKeyID: kp.KeyID,
+		Files:       files,
+		KeyRotation: rotation,
+	}
+	manifestBytes, err := yaml.Marshal(manifest)
+	if err != nil {
+		return "", err
+	}
+	hashSum := sha256.Sum256(manifestBytes)
+	hash := hex.EncodeToString(hashSum[:])
+
+	sig, err := sign(kp.PrivateKey, manifestBytes, digests)
+	if err != nil {
+		return "", err
+	}
+
+	outFile, err := os.Create(outPath)
+	if err != nil {
+		return "", err
+	}
+	defer outFile.Close()
+
+	tw := tar.NewWriter(outFile)
+	if err := writeTarEntry(tw, manifestName, manifestBytes); err != nil {
+		return "", err
+	}
+	if err := writeTarEntry(tw, signatureName, []byte(hex.EncodeToString(sig))); err != nil {
+		return "", err
+	}
+	for _, rel := range relPaths {
+		data, err := os.ReadFile(filepath.Join(dir, rel))
+		if err != nil {
+			return "", err
+		}
+		if err := writeTarEntry(tw, rel, data); err != nil {
+			return "", err
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return "", err
+	}
+	return hash, nil
+}
+
+func writeTarEntry(tw *tar.Writer, name string, data []byte) error {
+	if err := tw.WriteHeader(&tar.Header{Name: name, Size: int64(len(data)), Mode: 0o600}); err != nil {
+		return err
+	}
+	_, err := tw.Write(data)
+	return err
+}
+
+// Verify extracts bundlePath, checks its detached signature against pub
+// over the TupleHash-domain-separated manifest+payload digest, checks
+// every payload file's digest against the manifest, and enforces that
+// the manifest's prev_hash matches store's last-applied pointer.
+func Verify(bundlePath string, pub *secec.PublicKey, store *Store) (*VerifyResult, error) {
+	entries, err := readTar(bundlePath)
+	if err != nil {
+		return nil, err
+	}
+
+	manifestBytes, ok := entries[manifestName]
+	if !ok {
+		return nil, fmt.Errorf("bundle: missing %s", manifestName)
+	}
+	sigHex, ok := entries[signatureName]
+	if !ok {
+		return nil, fmt.Errorf("bundle: missing %s", signatureName)
+	}
+	sig, err := hex.DecodeString(string(sigHex))
+	if err != nil {
+		return nil, fmt.Errorf("bundle: decoding signature: %w", err)
+	}
+
+	var manifest Manifest
+	if err := yaml.Unmarshal(manifestBytes, &manifest); err != nil {
+		return nil, fmt.Errorf("bundle: parsing manifest: %w", err)
+	}
+
+	digests := make([][]byte, len(manifest.Files))
+	for i, fe := range manifest.Files {
+		payload, ok := entries[fe.Path]
+		if !ok {
+			return nil, fmt.Errorf("bundle: %s: %w", fe.Path, ErrPayloadTampered)
+		}
+		sum := sha256.Sum256(payload)
+		if hex.EncodeToString(sum[:]) != fe.SHA256 {
+			return nil, fmt.Errorf("bundle: %s: %w", fe.Path, ErrPayloadTampered)
+		}
+		digests[i] = sum[:]
+	}
+
+	if err := verifySignature(pub, manifestBytes, digests, sig); err != nil {
+		return nil, err
+	}
+
+	var newIssuerPubKey *secec.PublicKey
+	if manifest.KeyRotation != nil {
+		newIssuerPubKey, err = verifyKeyTransition(manifest.KeyRotation, pub)
+		if err != nil {
+			return nil, fmt.Errorf("bundle: verifying key rotation to %q: %w", manifest.KeyRotation.NewKeyID, err)
+		}
+	}
+
+	lastHash, _, hasHistory, err := store.LastApplied()
+	if err != nil {
+		return nil, err
+	}
+	if hasHistory && manifest.PrevHash != lastHash {
+		return nil, ErrChainMismatch
+	}
+	if !hasHistory && manifest.PrevHash != "" {
+		return nil, ErrChainMismatch
+	}
+
+	hashSum := sha256.Sum256(manifestBytes)
+	return &VerifyResult{Manifest: &manifest, Hash: hex.EncodeToString(hashSum[:]), NewIssuerPubKey: newIssuerPubKey}, nil
+}
+
+// Apply verifies bundlePath (see Verify), extracts its payload files
+// into destDir, and records it as the node's new last-applied bundle.
+func Apply(bundlePath, destDir string, pub *secec.PublicKey, store *Store) error {
+	result, err := Verify(bundlePath, pub, store)
+	if err != nil {
+		return err
+	}
+
+	entries, err := readTar(bundlePath)
+	if err != nil {
+		return err
+	}
+	for _, fe := range result.Manifest.Files {
+		dest := filepath.Join(destDir, fe.Path)
+		if err := os.MkdirAll(filepath.Dir(dest), 0o755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(dest, entries[fe.Path], 0o600); err != nil {
+			return err
+		}
+	}
+
+	return store.RecordApplied(result.Hash, result.Manifest.Version)
+}
+
+func readTar(path string) (map[string][]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	entries := make(map[string][]byte)
+	tr := tar.NewReader(f)
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		var buf bytes.Buffer
+		if _, err := io.Copy(&buf, tr); err != nil {
+			return nil, err
+		}
+		entries[hdr.Name] = buf.Bytes()
+	}
+	return entries, nil
+}
@@ -0,0 +1,158 @@
+package bundle
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func newTestBundle(t *testing.T, dir string, version uint64, prevHash string, kp *KeyPair, rotation *KeyTransition) (string, string) {
+	t.Helper()
+	payloadDir := filepath.Join(dir, "payload")
+	if err := os.MkdirAll(payloadDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(payloadDir, "app.bin"), []byte("v1 payload"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	outPath := filepath.Join(dir, "bundle.tar")
+	hash, err := Create(payloadDir, outPath, version, prevHash, kp, rotation)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return outPath, hash
+}
+
+func TestCreateVerifyApplyRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	kp, err := GenerateKeyPair("issuer-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	bundlePath, _ := newTestBundle(t, dir, 1, "", kp, nil)
+
+	store, err := OpenStore(filepath.Join(dir, "store.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+
+	destDir := filepath.Join(dir, "dest")
+	if err := Apply(bundlePath, destDir, kp.PrivateKey.PublicKey(), store); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := os.ReadFile(filepath.Join(destDir, "app.bin"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != "v1 payload" {
+		t.Fatalf("restored payload = %q, want %q", got, "v1 payload")
+	}
+}
+
+func TestVerifyRejectsWrongKey(t *testing.T) {
+	dir := t.TempDir()
+	kp, err := GenerateKeyPair("issuer-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := GenerateKeyPair("issuer-2")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	bundlePath, _ := newTestBundle(t, dir, 1, "", kp, nil)
+
+	store, err := OpenStore(filepath.Join(dir, "store.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+
+	if _, err := Verify(bundlePath, other.PrivateKey.PublicKey(), store); err == nil {
+		t.Fatal("expected Verify to reject a bundle signed by a different key")
+	}
+}
+
+func TestVerifyRejectsChainMismatch(t *testing.T) {
+	dir := t.TempDir()
+	kp, err := GenerateKeyPair("issuer-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	bundlePath, _ := newTestBundle(t, dir, 2, "not-the-real-prev-hash", kp, nil)
+
+	store, err := OpenStore(filepath.Join(dir, "store.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+
+	if _, err := Verify(bundlePath, kp.PrivateKey.PublicKey(), store); err != ErrChainMismatch {
+		t.Fatalf("Verify() err = %v, want %v", err, ErrChainMismatch)
+	}
+}
+
+func TestKeyRotationCountersignature(t *testing.T) {
+	dir := t.TempDir()
+	outgoing, err := GenerateKeyPair("issuer-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	incoming, err := GenerateKeyPair("issuer-2")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rotation, err := SignKeyTransition(outgoing, incoming.KeyID, incoming.PrivateKey.PublicKey())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	bundlePath, _ := newTestBundle(t, dir, 1, "", outgoing, rotation)
+
+	store, err := OpenStore(filepath.Join(dir, "store.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+
+	result, err := Verify(bundlePath, outgoing.PrivateKey.PublicKey(), store)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.NewIssuerPubKey == nil {
+		t.Fatal("expected Verify to surface the rotated-to public key")
+	}
+	if string(result.NewIssuerPubKey.Bytes()) != string(incoming.PrivateKey.PublicKey().Bytes()) {
+		t.Fatal("rotated-to public key does not match the incoming key")
+	}
+}
+
+func TestKeyRotationRejectsForgedCountersignature(t *testing.T) {
+	outgoing, err := GenerateKeyPair("issuer-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	incoming, err := GenerateKeyPair("issuer-2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	attacker, err := GenerateKeyPair("attacker")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rotation, err := SignKeyTransition(attacker, incoming.KeyID, incoming.PrivateKey.PublicKey())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := verifyKeyTransition(rotation, outgoing.PrivateKey.PublicKey()); err == nil {
+		t.Fatal("expected verifyKeyTransition to reject a countersignature from an untrusted key")
+	}
+}
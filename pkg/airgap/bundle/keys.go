@@ -0,0 +1,59 @@
+package bundle
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"gitlab.com/yawning/secp256k1-voi/secec"
+)
+
+// keyFile is the on-disk format for a persisted issuer signing key: the
+// operator-chosen key id plus the raw private scalar, hex-encoded so the
+// file is safe to move around as text.
+type keyFile struct {
+	KeyID      string `json:"key_id"`
+	PrivateKey string `json:"private_key"`
+}
+
+// SaveKeyPair persists kp to path, so later `bundle create` invocations
+// can sign under the same issuer key instead of each generating (and
+// discarding) a fresh one.
+func SaveKeyPair(kp *KeyPair, path string) error {
+	data, err := json.MarshalIndent(keyFile{
+		KeyID:      kp.KeyID,
+		PrivateKey: hex.EncodeToString(kp.PrivateKey.Bytes()),
+	}, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o600)
+}
+
+// LoadKeyPair reads a key persisted by SaveKeyPair.
+func LoadKeyPair(path string) (*KeyPair, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var kf keyFile
+	if err := json.Unmarshal(data, &kf); err != nil {
+		return nil, fmt.Errorf("bundle: parsing key file %s: %w", path, err)
+	}
+	raw, err := hex.DecodeString(kf.PrivateKey)
+	if err != nil {
+		return nil, fmt.Errorf("bundle: decoding private key in %s: %w", path, err)
+	}
+	priv, err := secec.NewPrivateKey(raw)
+	if err != nil {
+		return nil, fmt.Errorf("bundle: reconstructing private key from %s: %w", path, err)
+	}
+	return &KeyPair{KeyID: kf.KeyID, PrivateKey: priv}, nil
+}
+
+// PublicKeyHex returns kp's public key, hex-encoded the way
+// `oi bundle verify/apply --issuer-pubkey` expects it.
+func (kp *KeyPair) PublicKeyHex() string {
+	return hex.EncodeToString(kp.PrivateKey.PublicKey().Bytes())
+}
@@ -0,0 +1,33 @@
+package bundle
+
+// Manifest is the self-describing contents of MANIFEST.yaml: every
+// payload file's digest, the bundle's monotonic version, and the hash
+// of the bundle that must have been applied immediately before this one
+// — the link that makes the update history a chain rather than a bag of
+// independently-valid bundles.
+type Manifest struct {
+	Version     uint64         `yaml:"version"`
+	PrevHash    string         `yaml:"prev_hash,omitempty"`
+	IssuerKeyID string         `yaml:"issuer_key_id"`
+	Files       []FileEntry    `yaml:"files"`
+	KeyRotation *KeyTransition `yaml:"key_rotation,omitempty"`
+}
+
+// FileEntry is one payload file's digest and size, as recorded in the
+// manifest.
+type FileEntry struct {
+	Path   string `yaml:"path"`
+	SHA256 string `yaml:"sha256"`
+	Size   int64  `yaml:"size"`
+}
+
+// KeyTransition embeds a signed handoff from an outgoing issuer key to a
+// new one inside a bundle. The new key is countersigned by the outgoing
+// key so trust in the old key transfers forward without requiring an
+// out-of-band key exchange across the air gap.
+type KeyTransition struct {
+	NewKeyID         string `yaml:"new_key_id"`
+	NewPublicKey     string `yaml:"new_public_key"`   // hex-encoded uncompressed SEC 1 point
+	CountersignedBy  string `yaml:"countersigned_by"` // outgoing key id
+	Countersignature string `yaml:"countersignature"` // hex, outgoing key over NewPublicKey
+}
@@ -0,0 +1,107 @@
+package bundle
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+
+	"gitlab.com/yawning/secp256k1-voi/secec"
+)
+
+// signContext domain-separates bundle signatures from any other use of
+// the same issuer key, via TupleHash's customization string, so a
+// signature produced here can never be replayed as valid input to an
+// unrelated signing context.
+const signContext = "oi-airgap-bundle-v1"
+
+// rotationContext domain-separates an outgoing key's countersignature
+// over an incoming key's public key from signContext, so a key-rotation
+// countersignature can never be replayed as a valid bundle signature (or
+// vice versa) even though both are produced by the same issuer key.
+const rotationContext = "oi-airgap-key-rotation-v1"
+
+// KeyPair is an issuer's secp256k1 signing key, identified by KeyID (an
+// operator-chosen label such as "issuer-2026-q1", not derived from the
+// key itself).
+type KeyPair struct {
+	KeyID      string
+	PrivateKey *secec.PrivateKey
+}
+
+// GenerateKeyPair creates a new signing key for the given key id.
+func GenerateKeyPair(keyID string) (*KeyPair, error) {
+	priv, err := secec.GenerateKey()
+	if err != nil {
+		return nil, fmt.Errorf("bundle: generating signing key: %w", err)
+	}
+	return &KeyPair{KeyID: keyID, PrivateKey: priv}, nil
+}
+
+// digestForSigning computes the domain-separated digest that detached
+// signatures are computed over: a TupleHash of the manifest bytes and
+// every payload file's SHA-256 digest, in manifest order.
+func digestForSigning(manifestBytes []byte, fileDigests [][]byte) []byte {
+	tuple := make([][]byte, 0, len(fileDigests)+1)
+	tuple = append(tuple, manifestBytes)
+	tuple = append(tuple, fileDigests...)
+	return TupleHash256(tuple, 32, []byte(signContext))
+}
+
+// sign produces a detached signature over the bundle's signing digest.
+func sign(priv *secec.PrivateKey, manifestBytes []byte, fileDigests [][]byte) ([]byte, error) {
+	digest := digestForSigning(manifestBytes, fileDigests)
+	sig, err := priv.Sign(rand.Reader, digest, nil)
+	if err != nil {
+		return nil, fmt.Errorf("bundle: signing: %w", err)
+	}
+	return sig, nil
+}
+
+// verifySignature checks a detached signature against a trusted public
+// key over the same domain-separated digest sign() produced.
+func verifySignature(pub *secec.PublicKey, manifestBytes []byte, fileDigests [][]byte, sig []byte) error {
+	digest := digestForSigning(manifestBytes, fileDigests)
+	if !pub.Verify(digest, sig, nil) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+// SignKeyTransition has outgoing countersign newPub under newKeyID,
+// producing a KeyTransition that can be embedded in the next bundle
+// created under outgoing's key. A node that trusts outgoing's key can
+// verify this countersignature and adopt newPub as the issuer key for
+// every subsequent bundle, without needing an out-of-band key exchange
+// across the air gap.
+func SignKeyTransition(outgoing *KeyPair, newKeyID string, newPub *secec.PublicKey) (*KeyTransition, error) {
+	newPubBytes := newPub.Bytes()
+	digest := TupleHash256([][]byte{newPubBytes}, 32, []byte(rotationContext))
+	sig, err := outgoing.PrivateKey.Sign(rand.Reader, digest, nil)
+	if err != nil {
+		return nil, fmt.Errorf("bundle: countersigning key transition: %w", err)
+	}
+	return &KeyTransition{
+		NewKeyID:         newKeyID,
+		NewPublicKey:     hex.EncodeToString(newPubBytes),
+		CountersignedBy:  outgoing.KeyID,
+		Countersignature: hex.EncodeToString(sig),
+	}, nil
+}
+
+// verifyKeyTransition checks kt's countersignature against outgoingPub,
+// the key trusted for the bundle embedding kt.
+func verifyKeyTransition(kt *KeyTransition, outgoingPub *secec.PublicKey) (*secec.PublicKey, error) {
+	newPubBytes, err := hex.DecodeString(kt.NewPublicKey)
+	if err != nil {
+		return nil, fmt.Errorf("bundle: decoding key transition public key: %w", err)
+	}
+	sig, err := hex.DecodeString(kt.Countersignature)
+	if err != nil {
+		return nil, fmt.Errorf("bundle: decoding key transition countersignature: %w", err)
+	}
+	digest := TupleHash256([][]byte{newPubBytes}, 32, []byte(rotationContext))
+	if !outgoingPub.Verify(digest, sig, nil) {
+		return nil, ErrInvalidSignature
+	}
+	return secec.NewPublicKey(newPubBytes)
+}
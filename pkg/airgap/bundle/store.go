@@ -0,0 +1,68 @@
+package bundle
+
+import (
+	"errors"
+	"time"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// appliedBundle is the persisted record of one successfully applied
+// bundle and its chain hash, keyed by an auto-incremented ID in
+// application order.
+type appliedBundle struct {
+	ID        uint `gorm:"primarykey"`
+	Hash      string
+	Version   uint64
+	AppliedAt time.Time
+}
+
+// Store persists the "last applied" pointer that Verify checks every
+// incoming bundle's PrevHash against, so an air-gapped node can never be
+// rolled back or forked onto a different update history.
+type Store struct {
+	db *gorm.DB
+}
+
+// OpenStore opens (creating if necessary) the sqlite-backed applied
+// bundle ledger at path.
+func OpenStore(path string) (*Store, error) {
+	db, err := gorm.Open(sqlite.Open(path), &gorm.Config{})
+	if err != nil {
+		return nil, err
+	}
+	if err := db.AutoMigrate(&appliedBundle{}); err != nil {
+		return nil, err
+	}
+	return &Store{db: db}, nil
+}
+
+// LastApplied returns the hash and version of the most recently applied
+// bundle, or ok=false if none has been applied yet.
+func (s *Store) LastApplied() (hash string, version uint64, ok bool, err error) {
+	var rec appliedBundle
+	err = s.db.Order("id desc").First(&rec).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return "", 0, false, nil
+	}
+	if err != nil {
+		return "", 0, false, err
+	}
+	return rec.Hash, rec.Version, true, nil
+}
+
+// RecordApplied records that the bundle identified by hash/version has
+// been applied.
+func (s *Store) RecordApplied(hash string, version uint64) error {
+	return s.db.Create(&appliedBundle{Hash: hash, Version: version, AppliedAt: time.Now()}).Error
+}
+
+// Close releases the underlying database connection.
+func (s *Store) Close() error {
+	sqlDB, err := s.db.DB()
+	if err != nil {
+		return err
+	}
+	return sqlDB.Close()
+}
@@ -0,0 +1,60 @@
+package bundle
+
+import "golang.org/x/crypto/sha3"
+
+// TupleHash256 implements TupleHash256 (NIST SP 800-185) over cSHAKE256:
+// each element of tuple is length-prefixed with left_encode(len(element)*8)
+// before hashing, and the customization string S provides domain
+// separation so a signature produced for one bundle context (e.g.
+// "oi-airgap-bundle-v1") can never be replayed as valid input for
+// another. outputBytes is the requested digest length. Per the spec,
+// cSHAKE's function-name parameter N is the fixed string "TupleHash",
+// kept separate from the customization string S — not concatenated
+// with it — so this matches other conformant TupleHash256 outputs.
+func TupleHash256(tuple [][]byte, outputBytes int, customization []byte) []byte {
+	h := sha3.NewCShake256([]byte("TupleHash"), customization)
+	for _, elem := range tuple {
+		h.Write(encodeString(elem))
+	}
+	h.Write(rightEncode(uint64(outputBytes) * 8))
+
+	out := make([]byte, outputBytes)
+	h.Read(out)
+	return out
+}
+
+// leftEncode implements NIST SP 800-185's left_encode: the big-endian
+// byte representation of x, prefixed with its own length in one byte.
+func leftEncode(x uint64) []byte {
+	b := encodeUint(x)
+	return append([]byte{byte(len(b))}, b...)
+}
+
+// rightEncode is left_encode with the length byte moved to the end.
+func rightEncode(x uint64) []byte {
+	b := encodeUint(x)
+	return append(b, byte(len(b)))
+}
+
+// encodeUint returns the minimal big-endian encoding of x (at least one
+// byte, even for x == 0).
+func encodeUint(x uint64) []byte {
+	if x == 0 {
+		return []byte{0}
+	}
+	var buf [8]byte
+	n := 0
+	for i := 7; i >= 0; i-- {
+		buf[i] = byte(x)
+		x >>= 8
+		if buf[i] != 0 {
+			n = 8 - i
+		}
+	}
+	return buf[8-n:]
+}
+
+// encodeString implements encode_string: left_encode(len(s)*8) || s.
+func encodeString(s []byte) []byte {
+	return append(leftEncode(uint64(len(s))*8), s...)
+}
@@ -0,0 +1,214 @@
+// Package policy evaluates zero-trust access decisions against rules
+// written in CEL (Common Expression Language), the same way Caddy uses
+// CEL for its matcher expressions. Rules are plain YAML `when`/`allow`
+// blocks; compiled programs are cached by rule hash so re-evaluating the
+// same bundle (the common case — one bundle, many requests) never pays
+// the compile cost twice.
+package policy
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/common/types/ref"
+)
+
+// EntropySource reports the current estimated bits of available system
+// entropy, so rules can gate sensitive capabilities on the air-gapped
+// node's RNG being adequately seeded (e.g. `entropy_ok(256)`).
+type EntropySource interface {
+	Bits() int
+}
+
+// staticEntropySource is the default EntropySource used when none is
+// configured: it reports a safely high value so entropy_ok() is a no-op
+// until an operator wires up a real source.
+type staticEntropySource int
+
+func (s staticEntropySource) Bits() int { return int(s) }
+
+// Engine evaluates a compiled rule set. It is safe for concurrent use.
+type Engine struct {
+	env     *cel.Env
+	entropy EntropySource
+
+	mu       sync.RWMutex
+	rules    []Rule
+	programs map[string]cel.Program
+}
+
+// Option configures an Engine constructed by NewEngine.
+type Option func(*Engine)
+
+// WithEntropySource overrides the EntropySource backing the entropy_ok()
+// CEL function.
+func WithEntropySource(s EntropySource) Option {
+	return func(e *Engine) { e.entropy = s }
+}
+
+// NewEngine builds an Engine for the given rules, declaring the CEL
+// environment's subject/resource/action/now variables and the
+// entropy_ok() helper.
+func NewEngine(rules []Rule, opts ...Option) (*Engine, error) {
+	e := &Engine{
+		rules:    rules,
+		programs: make(map[string]cel.Program, len(rules)),
+		entropy:  staticEntropySource(256),
+	}
+	for _, opt := range opts {
+		opt(e)
+	}
+
+	env, err := cel.NewEnv(
+		cel.Variable("subject", cel.MapType(cel.StringType, cel.DynType)),
+		cel.Variable("resource", cel.MapType(cel.StringType, cel.DynType)),
+		cel.Variable("action", cel.StringType),
+		cel.Variable("now", cel.TimestampType),
+		cel.Function("entropy_ok",
+			cel.Overload("entropy_ok_int", []*cel.Type{cel.IntType}, cel.BoolType,
+				cel.UnaryBinding(func(arg ref.Val) ref.Val {
+					minBits, ok := arg.Value().(int64)
+					if !ok {
+						return types.NewErr("entropy_ok: argument must be an int")
+					}
+					return types.Bool(e.entropy.Bits() >= int(minBits))
+				}),
+			),
+		),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("policy: building CEL environment: %w", err)
+	}
+	e.env = env
+
+	for _, r := range rules {
+		if _, err := e.compile(r); err != nil {
+			return nil, fmt.Errorf("policy: rule %q: %w", r.Name, err)
+		}
+	}
+	return e, nil
+}
+
+// ruleHash identifies a rule by the content of its `when` expression, so
+// the program cache survives rule reordering and only recompiles rules
+// whose expression text actually changed.
+func ruleHash(r Rule) string {
+	sum := sha256.Sum256([]byte(r.When))
+	return hex.EncodeToString(sum[:])
+}
+
+func (e *Engine) compile(r Rule) (cel.Program, error) {
+	hash := ruleHash(r)
+
+	e.mu.RLock()
+	if p, ok := e.programs[hash]; ok {
+		e.mu.RUnlock()
+		return p, nil
+	}
+	e.mu.RUnlock()
+
+	ast, issues := e.env.Compile(r.When)
+	if issues != nil && issues.Err() != nil {
+		return nil, fmt.Errorf("compiling %q: %w", r.When, issues.Err())
+	}
+	program, err := e.env.Program(ast)
+	if err != nil {
+		return nil, fmt.Errorf("building program for %q: %w", r.When, err)
+	}
+
+	e.mu.Lock()
+	e.programs[hash] = program
+	e.mu.Unlock()
+	return program, nil
+}
+
+func activationFor(subject Subject, resource Resource, action string) map[string]interface{} {
+	return map[string]interface{}{
+		"subject":  subjectToMap(subject),
+		"resource": resourceToMap(resource),
+		"action":   action,
+		"now":      time.Now(),
+	}
+}
+
+// EvaluateRule runs a single rule's `when` expression against the given
+// subject/resource/action, using the engine's compiled-program cache
+// (compiling and caching it first if this is the rule's first
+// evaluation). It is exported so callers that need a per-rule
+// hit/miss/deny breakdown — `oi policy test` via DryRun — can get one
+// without bypassing the cache the way building a throwaway
+// single-rule Engine per call would.
+func (e *Engine) EvaluateRule(ctx context.Context, r Rule, subject Subject, resource Resource, action string) (bool, error) {
+	program, err := e.compile(r)
+	if err != nil {
+		return false, err
+	}
+	out, _, err := program.ContextEval(ctx, activationFor(subject, resource, action))
+	if err != nil {
+		return false, fmt.Errorf("policy: evaluating rule %q: %w", r.Name, err)
+	}
+	matched, ok := out.Value().(bool)
+	if !ok {
+		return false, fmt.Errorf("policy: rule %q did not evaluate to a bool", r.Name)
+	}
+	return matched, nil
+}
+
+// Evaluate runs every rule's `when` expression against the given
+// subject/resource/action and unions the `allow` capabilities of every
+// rule that matched. The decision is Allowed only if at least one rule
+// matched.
+func (e *Engine) Evaluate(ctx context.Context, subject Subject, resource Resource, action string) (Decision, error) {
+	var decision Decision
+	grantSeen := make(map[string]bool)
+
+	for _, r := range e.rules {
+		matched, err := e.EvaluateRule(ctx, r, subject, resource, action)
+		if err != nil {
+			return Decision{}, err
+		}
+		if !matched {
+			continue
+		}
+
+		decision.Allowed = true
+		decision.Matched = append(decision.Matched, r.Name)
+		for _, cap := range r.Allow {
+			if !grantSeen[cap] {
+				grantSeen[cap] = true
+				decision.Grants = append(decision.Grants, cap)
+			}
+		}
+	}
+	return decision, nil
+}
+
+func subjectToMap(s Subject) map[string]interface{} {
+	measurements := make(map[string]interface{}, len(s.Measurements))
+	for k, v := range s.Measurements {
+		measurements[k] = v
+	}
+	return map[string]interface{}{
+		"identity":     s.Identity,
+		"measurements": measurements,
+		"zone":         s.Zone,
+	}
+}
+
+func resourceToMap(r Resource) map[string]interface{} {
+	metadata := make(map[string]interface{}, len(r.Metadata))
+	for k, v := range r.Metadata {
+		metadata[k] = v
+	}
+	return map[string]interface{}{
+		"kind":     r.Kind,
+		"id":       r.ID,
+		"metadata": metadata,
+	}
+}
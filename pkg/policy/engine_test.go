@@ -0,0 +1,82 @@
+package policy
+
+import (
+	"context"
+	"testing"
+)
+
+func testRules() []Rule {
+	return []Rule{
+		{Name: "allow-zone-a", When: `subject.zone == "zone-a"`, Allow: []string{"read"}},
+		{Name: "allow-admin", When: `subject.identity == "admin"`, Allow: []string{"read", "write"}},
+	}
+}
+
+func TestEngineEvaluate(t *testing.T) {
+	engine, err := NewEngine(testRules())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	decision, err := engine.Evaluate(context.Background(), Subject{Identity: "alice", Zone: "zone-a"}, Resource{Kind: "file", ID: "f1"}, "read")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !decision.Allowed {
+		t.Fatal("expected zone-a subject to be allowed")
+	}
+	if len(decision.Matched) != 1 || decision.Matched[0] != "allow-zone-a" {
+		t.Fatalf("matched = %v, want [allow-zone-a]", decision.Matched)
+	}
+
+	decision, err = engine.Evaluate(context.Background(), Subject{Identity: "bob", Zone: "zone-b"}, Resource{Kind: "file", ID: "f1"}, "read")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if decision.Allowed {
+		t.Fatal("expected zone-b non-admin subject to be denied")
+	}
+}
+
+func TestEngineEvaluateRuleReusesCache(t *testing.T) {
+	engine, err := NewEngine(testRules())
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := testRules()[0]
+
+	before := len(engine.programs)
+	if _, err := engine.EvaluateRule(context.Background(), r, Subject{Zone: "zone-a"}, Resource{}, "read"); err != nil {
+		t.Fatal(err)
+	}
+	if len(engine.programs) != before {
+		t.Fatalf("EvaluateRule compiled a new program: programs went from %d to %d", before, len(engine.programs))
+	}
+}
+
+func TestDryRun(t *testing.T) {
+	rules := testRules()
+	fixtures := []Fixture{
+		{Name: "zone-a-read", Subject: Subject{Identity: "alice", Zone: "zone-a"}, Resource: Resource{Kind: "file", ID: "f1"}, Action: "read"},
+		{Name: "admin-write", Subject: Subject{Identity: "admin", Zone: "zone-b"}, Resource: Resource{Kind: "file", ID: "f1"}, Action: "write"},
+	}
+
+	results, err := DryRun(context.Background(), rules, fixtures)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != len(fixtures) {
+		t.Fatalf("got %d results, want %d", len(results), len(fixtures))
+	}
+
+	if !results[0].Decision.Allowed {
+		t.Fatal("expected zone-a-read fixture to be allowed")
+	}
+	if len(results[0].Rules) != len(rules) {
+		t.Fatalf("got %d per-rule outcomes, want %d", len(results[0].Rules), len(rules))
+	}
+
+	if !results[1].Decision.Allowed {
+		t.Fatal("expected admin-write fixture to be allowed")
+	}
+}
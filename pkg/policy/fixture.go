@@ -0,0 +1,76 @@
+package policy
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// Fixture is one scenario in a `oi policy test` fixture set: a
+// subject/resource/action triple to evaluate against a rule bundle.
+type Fixture struct {
+	Name     string   `json:"name"`
+	Subject  Subject  `json:"subject"`
+	Resource Resource `json:"resource"`
+	Action   string   `json:"action"`
+}
+
+// FixtureSet is the top-level shape of a `oi policy test` fixture file.
+type FixtureSet struct {
+	Cases []Fixture `json:"cases"`
+}
+
+// LoadFixtures parses a JSON fixture file.
+func LoadFixtures(data []byte) ([]Fixture, error) {
+	var set FixtureSet
+	if err := json.Unmarshal(data, &set); err != nil {
+		return nil, fmt.Errorf("policy: parsing fixture set: %w", err)
+	}
+	return set.Cases, nil
+}
+
+// RuleOutcome is one rule's result against one fixture case.
+type RuleOutcome struct {
+	Rule    string
+	Matched bool
+	Err     error
+}
+
+// FixtureResult is a fixture case's outcome across every rule in the
+// bundle, plus the aggregate Decision an Engine.Evaluate call would
+// produce for it.
+type FixtureResult struct {
+	Fixture  Fixture
+	Rules    []RuleOutcome
+	Decision Decision
+}
+
+// DryRun evaluates every rule in the bundle against every fixture case
+// individually (so a denied/non-matching rule can still be reported),
+// in addition to the aggregate Decision. It is what `oi policy test`
+// uses to print its per-rule hit/miss/deny report.
+func DryRun(ctx context.Context, rules []Rule, fixtures []Fixture) ([]FixtureResult, error) {
+	engine, err := NewEngine(rules)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]FixtureResult, 0, len(fixtures))
+	for _, fx := range fixtures {
+		fr := FixtureResult{Fixture: fx}
+
+		for _, r := range rules {
+			matched, err := engine.EvaluateRule(ctx, r, fx.Subject, fx.Resource, fx.Action)
+			fr.Rules = append(fr.Rules, RuleOutcome{Rule: r.Name, Matched: matched, Err: err})
+		}
+
+		decision, err := engine.Evaluate(ctx, fx.Subject, fx.Resource, fx.Action)
+		if err != nil {
+			return nil, err
+		}
+		fr.Decision = decision
+
+		results = append(results, fr)
+	}
+	return results, nil
+}
@@ -0,0 +1,35 @@
+package policy
+
+import (
+	"fmt"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Rule is one `when: <cel-expr>` / `allow: [caps...]` block. Name is
+// optional but strongly recommended: it is what `oi policy test` prints
+// in its per-rule hit/miss/deny report.
+type Rule struct {
+	Name  string   `yaml:"name"`
+	When  string   `yaml:"when"`
+	Allow []string `yaml:"allow"`
+}
+
+// RuleSet is the top-level shape of a policy YAML document.
+type RuleSet struct {
+	Rules []Rule `yaml:"rules"`
+}
+
+// LoadRules parses a policy YAML document into its rule list.
+func LoadRules(data []byte) ([]Rule, error) {
+	var set RuleSet
+	if err := yaml.Unmarshal(data, &set); err != nil {
+		return nil, fmt.Errorf("policy: parsing rule bundle: %w", err)
+	}
+	for i, r := range set.Rules {
+		if r.When == "" {
+			return nil, fmt.Errorf("policy: rule %d (%q) has no `when` expression", i, r.Name)
+		}
+	}
+	return set.Rules, nil
+}
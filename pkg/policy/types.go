@@ -0,0 +1,26 @@
+package policy
+
+// Subject describes the caller a policy decision is being made about:
+// who they claim to be, what attestation measurements were collected
+// for them, and which air-gap zone they are physically operating in.
+type Subject struct {
+	Identity     string            `json:"identity"`
+	Measurements map[string]string `json:"measurements,omitempty"`
+	Zone         string            `json:"zone"`
+}
+
+// Resource describes the object a capability decision is being made
+// against.
+type Resource struct {
+	Kind     string            `json:"kind"`
+	ID       string            `json:"id"`
+	Metadata map[string]string `json:"metadata,omitempty"`
+}
+
+// Decision is the outcome of evaluating a rule set against a
+// subject/resource/action triple.
+type Decision struct {
+	Allowed bool     `json:"allowed"`
+	Grants  []string `json:"grants,omitempty"`
+	Matched []string `json:"matched_rules,omitempty"`
+}
@@ -0,0 +1,12 @@
+package snapshot
+
+// Backend is a pluggable content-addressable chunk store. Local and
+// sneakernet backends both satisfy it except WriteOnly ones, which only
+// support Put/Has and return ErrWriteOnly from Get/List/Delete.
+type Backend interface {
+	Put(id string, data []byte) error
+	Get(id string) ([]byte, error)
+	Has(id string) (bool, error)
+	List() ([]string, error)
+	Delete(id string) error
+}
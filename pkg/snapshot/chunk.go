@@ -0,0 +1,81 @@
+package snapshot
+
+import (
+	"bufio"
+	"io"
+)
+
+const (
+	minChunkSize = 1 << 10  // 1 KiB
+	avgChunkSize = 4 << 10  // 4 KiB
+	maxChunkSize = 16 << 10 // 16 KiB
+	windowSize   = 48
+
+	// rollingBase is the multiplier of the rolling polynomial hash. It
+	// is odd so every power of it stays odd (and therefore invertible)
+	// mod 2^64, which keeps the hash well distributed across the
+	// uint64 range as bytes roll through the window.
+	rollingBase = uint64(1099511628211)
+)
+
+// splitMask selects roughly 1-in-avgChunkSize cut points, since
+// avgChunkSize is a power of two: a uniformly distributed hash has
+// probability 1/avgChunkSize of having its low log2(avgChunkSize) bits
+// all zero.
+var splitMask = uint64(avgChunkSize - 1)
+
+// splitChunks implements content-defined chunking with a Rabin-style
+// rolling polynomial hash over a sliding window of the last windowSize
+// bytes: the stream is cut wherever that hash satisfies
+// hash&splitMask == 0, bounded to [minChunkSize, maxChunkSize]. Unlike
+// fixed-size chunking, inserting or deleting a byte anywhere in the
+// input only ever perturbs the chunks touching that byte, not every
+// chunk after it — the property convergent, dedup-friendly storage
+// depends on.
+func splitChunks(r io.Reader) ([][]byte, error) {
+	br := bufio.NewReaderSize(r, 64*1024)
+
+	var pow uint64 = 1
+	for i := 0; i < windowSize; i++ {
+		pow *= rollingBase
+	}
+
+	var chunks [][]byte
+	var current []byte
+	var window [windowSize]byte
+	var windowPos int
+	var hash uint64
+
+	resetWindow := func() {
+		window = [windowSize]byte{}
+		windowPos = 0
+		hash = 0
+	}
+
+	for {
+		b, err := br.ReadByte()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		current = append(current, b)
+
+		old := window[windowPos]
+		window[windowPos] = b
+		windowPos = (windowPos + 1) % windowSize
+		hash = hash*rollingBase - uint64(old)*pow + uint64(b)
+
+		atCut := len(current) >= minChunkSize && hash&splitMask == 0
+		if atCut || len(current) >= maxChunkSize {
+			chunks = append(chunks, current)
+			current = nil
+			resetWindow()
+		}
+	}
+	if len(current) > 0 {
+		chunks = append(chunks, current)
+	}
+	return chunks, nil
+}
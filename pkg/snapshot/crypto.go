@@ -0,0 +1,112 @@
+package snapshot
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+
+	"golang.org/x/crypto/blake2b"
+	"golang.org/x/crypto/chacha20poly1305"
+	"golang.org/x/crypto/hkdf"
+)
+
+// plainHash returns the BLAKE2b-256 digest of a chunk's plaintext. It
+// doubles as both the convergent-encryption key material input and the
+// manifest's reference to the chunk's content, independent of how it
+// was encrypted.
+func plainHash(data []byte) [blake2b.Size256]byte {
+	return blake2b.Sum256(data)
+}
+
+// chunkKey derives a per-chunk XChaCha20-Poly1305 key from the master
+// key and the chunk's plaintext hash via HKDF. Two snapshots containing
+// the same plaintext chunk therefore derive the same key and produce
+// the same ciphertext, enabling convergent deduplication across
+// unrelated snapshots without ever comparing plaintext.
+func chunkKey(master []byte, ph [blake2b.Size256]byte) ([]byte, error) {
+	reader := hkdf.New(sha256.New, master, ph[:], []byte("oi-snapshot-chunk-v1"))
+	key := make([]byte, chacha20poly1305.KeySize)
+	if _, err := io.ReadFull(reader, key); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// sealChunk encrypts a chunk's plaintext under its convergent key using
+// a fixed all-zero nonce. This is only safe because the key itself is a
+// one-time pad over the plaintext's hash: the same (key, nonce) pair
+// only ever encrypts the exact same plaintext, which is the entire
+// point of convergent encryption — reusing the nonce here never reuses
+// a (key, plaintext) pair with different contents.
+func sealChunk(master []byte, plaintext []byte) (addr string, ciphertext []byte, ph [blake2b.Size256]byte, err error) {
+	ph = plainHash(plaintext)
+	key, err := chunkKey(master, ph)
+	if err != nil {
+		return "", nil, ph, err
+	}
+	aead, err := chacha20poly1305.NewX(key)
+	if err != nil {
+		return "", nil, ph, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	ciphertext = aead.Seal(nil, nonce, plaintext, nil)
+
+	addrSum := blake2b.Sum256(ciphertext)
+	return hex.EncodeToString(addrSum[:]), ciphertext, ph, nil
+}
+
+// openChunk decrypts a chunk previously sealed by sealChunk, given the
+// plaintext hash recorded for it in the manifest.
+func openChunk(master []byte, ph [blake2b.Size256]byte, ciphertext []byte) ([]byte, error) {
+	key, err := chunkKey(master, ph)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := chacha20poly1305.NewX(key)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	plaintext, err := aead.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return nil, fmt.Errorf("snapshot: decrypting chunk: %w", err)
+	}
+	if plainHash(plaintext) != ph {
+		return nil, fmt.Errorf("snapshot: decrypted chunk does not match recorded plaintext hash")
+	}
+	return plaintext, nil
+}
+
+// rootKey derives the key behind snapshotID from the master key via
+// HKDF, domain-separated from chunkKey so a snapshot id can never be
+// confused with a chunk's encryption key.
+func rootKey(master []byte) ([]byte, error) {
+	reader := hkdf.New(sha256.New, master, nil, []byte("oi-snapshot-root-v1"))
+	key := make([]byte, blake2b.Size256)
+	if _, err := io.ReadFull(reader, key); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// snapshotID authenticates a snapshot's Merkle root together with its
+// manifest bytes under a key derived from master, via a keyed BLAKE2b
+// MAC: the manifest (file paths, modes, and chunk assignment) is
+// otherwise stored unauthenticated in the Backend, so binding it into
+// the id is what stops a corrupted or swapped manifest from restoring
+// under a different, attacker-chosen file layout while still matching
+// the snapshot id a caller asked for.
+func snapshotID(master []byte, root [blake2b.Size256]byte, manifestBytes []byte) (string, error) {
+	key, err := rootKey(master)
+	if err != nil {
+		return "", err
+	}
+	mac, err := blake2b.New256(key)
+	if err != nil {
+		return "", err
+	}
+	mac.Write(root[:])
+	mac.Write(manifestBytes)
+	return hex.EncodeToString(mac.Sum(nil)), nil
+}
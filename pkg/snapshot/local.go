@@ -0,0 +1,59 @@
+package snapshot
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// LocalBackend stores chunks as individual files, named by their
+// content address, under a directory.
+type LocalBackend struct {
+	dir string
+}
+
+// NewLocalBackend opens (creating if necessary) a local directory chunk
+// store.
+func NewLocalBackend(dir string) (*LocalBackend, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	return &LocalBackend{dir: dir}, nil
+}
+
+func (b *LocalBackend) path(id string) string {
+	return filepath.Join(b.dir, id)
+}
+
+func (b *LocalBackend) Put(id string, data []byte) error {
+	return os.WriteFile(b.path(id), data, 0o600)
+}
+
+func (b *LocalBackend) Get(id string) ([]byte, error) {
+	return os.ReadFile(b.path(id))
+}
+
+func (b *LocalBackend) Has(id string) (bool, error) {
+	_, err := os.Stat(b.path(id))
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func (b *LocalBackend) List() ([]string, error) {
+	entries, err := os.ReadDir(b.dir)
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]string, 0, len(entries))
+	for _, e := range entries {
+		if !e.IsDir() {
+			ids = append(ids, e.Name())
+		}
+	}
+	return ids, nil
+}
+
+func (b *LocalBackend) Delete(id string) error {
+	return os.Remove(b.path(id))
+}
@@ -0,0 +1,29 @@
+package snapshot
+
+import "golang.org/x/crypto/blake2b"
+
+// merkleRoot builds a binary Merkle tree over leaves (in order) and
+// returns its root. A level with an odd node count duplicates the last
+// node, the common fix for unbalanced trees (as used by, e.g., Bitcoin).
+// An empty leaf set hashes to the BLAKE2b-256 of nothing.
+func merkleRoot(leaves [][blake2b.Size256]byte) [blake2b.Size256]byte {
+	if len(leaves) == 0 {
+		return blake2b.Sum256(nil)
+	}
+
+	level := leaves
+	for len(level) > 1 {
+		if len(level)%2 == 1 {
+			level = append(level, level[len(level)-1])
+		}
+		next := make([][blake2b.Size256]byte, len(level)/2)
+		for i := 0; i < len(next); i++ {
+			var buf [2 * blake2b.Size256]byte
+			copy(buf[:blake2b.Size256], level[2*i][:])
+			copy(buf[blake2b.Size256:], level[2*i+1][:])
+			next[i] = blake2b.Sum256(buf[:])
+		}
+		level = next
+	}
+	return level[0]
+}
@@ -0,0 +1,297 @@
+// Package snapshot provides content-addressable, convergently encrypted
+// snapshot/restore of the mini-OS state directory, modeled on
+// kopia-style content-addressable storage: files are split into
+// variable-size chunks by a rolling hash, each chunk is addressed by
+// the BLAKE2b hash of its ciphertext and encrypted with
+// XChaCha20-Poly1305 keyed by HKDF(master, chunk-plaintext-hash) so
+// identical plaintext chunks always dedup to the same stored object,
+// and the ordered chunk addresses are assembled into a Merkle tree
+// whose root, MACed together with the manifest under a key derived from
+// the master key, is the snapshot id.
+package snapshot
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"golang.org/x/crypto/blake2b"
+)
+
+// ErrManifestTampered is returned by Restore when a manifest's
+// recomputed snapshot id doesn't match the one it was fetched under,
+// meaning the manifest (or the chunk set it references) was corrupted
+// or swapped after Create wrote it.
+var ErrManifestTampered = errors.New("snapshot: manifest failed integrity check")
+
+// ChunkRef is a file's reference to one of its chunks: PlainHash is
+// needed to re-derive the chunk's decryption key; Addr is where its
+// ciphertext lives in the Backend.
+type ChunkRef struct {
+	Addr      string `json:"addr"`
+	PlainHash string `json:"plain_hash"`
+	Length    int    `json:"length"`
+}
+
+// FileEntry is one file's path, permissions, and ordered chunk list.
+type FileEntry struct {
+	Path   string     `json:"path"`
+	Mode   uint32     `json:"mode"`
+	Chunks []ChunkRef `json:"chunks"`
+}
+
+// Manifest is the snapshot-wide index of every file and its chunks. It
+// is itself stored in the Backend under the snapshot's root id.
+type Manifest struct {
+	Files []FileEntry `json:"files"`
+}
+
+// Create snapshots every regular file under dir into backend, returning
+// the snapshot id: a master-keyed MAC over the Merkle root of every
+// chunk address (in file and chunk order) and the manifest bytes
+// themselves, so the id authenticates not just which chunks exist but
+// which file path and mode each one was assigned to (see snapshotID).
+// onProgress, if non-nil, is called after each file is chunked and
+// sealed with the number of files done and the total.
+func Create(dir string, backend Backend, master []byte, onProgress func(done, total int)) (string, error) {
+	var relPaths []string
+	if err := filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		rel, err := filepath.Rel(dir, p)
+		if err != nil {
+			return err
+		}
+		relPaths = append(relPaths, rel)
+		return nil
+	}); err != nil {
+		return "", fmt.Errorf("snapshot: walking %s: %w", dir, err)
+	}
+	sort.Strings(relPaths)
+
+	var leaves [][blake2b.Size256]byte
+	files := make([]FileEntry, 0, len(relPaths))
+
+	for _, rel := range relPaths {
+		full := filepath.Join(dir, rel)
+		info, err := os.Stat(full)
+		if err != nil {
+			return "", err
+		}
+		f, err := os.Open(full)
+		if err != nil {
+			return "", err
+		}
+		chunks, err := splitChunks(f)
+		f.Close()
+		if err != nil {
+			return "", err
+		}
+
+		fe := FileEntry{Path: rel, Mode: uint32(info.Mode().Perm())}
+		for _, plain := range chunks {
+			addr, ciphertext, ph, err := sealChunk(master, plain)
+			if err != nil {
+				return "", err
+			}
+			has, err := backend.Has(addr)
+			if err != nil {
+				return "", err
+			}
+			if !has {
+				if err := backend.Put(addr, ciphertext); err != nil {
+					return "", err
+				}
+			}
+			fe.Chunks = append(fe.Chunks, ChunkRef{Addr: addr, PlainHash: hex.EncodeToString(ph[:]), Length: len(plain)})
+
+			addrBytes, err := hex.DecodeString(addr)
+			if err != nil {
+				return "", err
+			}
+			var leaf [blake2b.Size256]byte
+			copy(leaf[:], addrBytes)
+			leaves = append(leaves, leaf)
+		}
+		files = append(files, fe)
+		if onProgress != nil {
+			onProgress(len(files), len(relPaths))
+		}
+	}
+
+	root := merkleRoot(leaves)
+
+	manifestBytes, err := json.Marshal(Manifest{Files: files})
+	if err != nil {
+		return "", err
+	}
+	rootID, err := snapshotID(master, root, manifestBytes)
+	if err != nil {
+		return "", err
+	}
+	if err := backend.Put(rootID, manifestBytes); err != nil {
+		return "", err
+	}
+	return rootID, nil
+}
+
+// Restore reconstructs every file recorded in rootID's manifest into
+// destDir. It first recomputes rootID from the fetched manifest bytes
+// (see snapshotID) and rejects the restore if they don't match, since
+// the manifest is stored unauthenticated in the Backend and a corrupted
+// or swapped one could otherwise remap chunks onto arbitrary paths.
+func Restore(rootID string, destDir string, backend Backend, master []byte) error {
+	manifestBytes, err := backend.Get(rootID)
+	if err != nil {
+		return fmt.Errorf("snapshot: fetching manifest %s: %w", rootID, err)
+	}
+	var manifest Manifest
+	if err := json.Unmarshal(manifestBytes, &manifest); err != nil {
+		return fmt.Errorf("snapshot: parsing manifest %s: %w", rootID, err)
+	}
+
+	var leaves [][blake2b.Size256]byte
+	for _, fe := range manifest.Files {
+		if err := validateRelPath(fe.Path); err != nil {
+			return err
+		}
+		for _, ref := range fe.Chunks {
+			addrBytes, err := validateAddr(ref.Addr)
+			if err != nil {
+				return err
+			}
+			var leaf [blake2b.Size256]byte
+			copy(leaf[:], addrBytes)
+			leaves = append(leaves, leaf)
+		}
+	}
+	root := merkleRoot(leaves)
+	wantID, err := snapshotID(master, root, manifestBytes)
+	if err != nil {
+		return err
+	}
+	if wantID != rootID {
+		return fmt.Errorf("snapshot: manifest %s failed integrity check (got %s): %w", rootID, wantID, ErrManifestTampered)
+	}
+
+	for _, fe := range manifest.Files {
+		dest := filepath.Join(destDir, fe.Path)
+		if err := os.MkdirAll(filepath.Dir(dest), 0o755); err != nil {
+			return err
+		}
+		out, err := os.OpenFile(dest, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, os.FileMode(fe.Mode))
+		if err != nil {
+			return err
+		}
+		for _, ref := range fe.Chunks {
+			ciphertext, err := backend.Get(ref.Addr)
+			if err != nil {
+				out.Close()
+				return fmt.Errorf("snapshot: fetching chunk %s: %w", ref.Addr, err)
+			}
+			phBytes, err := hex.DecodeString(ref.PlainHash)
+			if err != nil {
+				out.Close()
+				return err
+			}
+			var ph [blake2b.Size256]byte
+			copy(ph[:], phBytes)
+			plain, err := openChunk(master, ph, ciphertext)
+			if err != nil {
+				out.Close()
+				return err
+			}
+			if _, err := out.Write(plain); err != nil {
+				out.Close()
+				return err
+			}
+		}
+		if err := out.Close(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GC deletes every chunk in backend that is not referenced, directly or
+// via its manifest, by any root in keepRoots. It returns the ids it
+// removed.
+func GC(backend Backend, keepRoots []string) ([]string, error) {
+	used := make(map[string]bool, len(keepRoots))
+	for _, root := range keepRoots {
+		used[root] = true
+		manifestBytes, err := backend.Get(root)
+		if err != nil {
+			return nil, fmt.Errorf("snapshot: fetching manifest %s: %w", root, err)
+		}
+		var manifest Manifest
+		if err := json.Unmarshal(manifestBytes, &manifest); err != nil {
+			return nil, fmt.Errorf("snapshot: parsing manifest %s: %w", root, err)
+		}
+		for _, fe := range manifest.Files {
+			for _, ref := range fe.Chunks {
+				used[ref.Addr] = true
+			}
+		}
+	}
+
+	all, err := backend.List()
+	if err != nil {
+		return nil, err
+	}
+
+	var removed []string
+	for _, id := range all {
+		if used[id] {
+			continue
+		}
+		if err := backend.Delete(id); err != nil {
+			return nil, err
+		}
+		removed = append(removed, id)
+	}
+	return removed, nil
+}
+
+// validateRelPath rejects any manifest file path that isn't a clean,
+// relative path, so a tampered manifest can't make Restore write
+// outside destDir via an absolute path or a "../" traversal.
+func validateRelPath(p string) error {
+	if p == "" {
+		return fmt.Errorf("snapshot: empty file path in manifest")
+	}
+	if filepath.IsAbs(p) {
+		return fmt.Errorf("snapshot: absolute file path %q in manifest", p)
+	}
+	cleaned := filepath.Clean(p)
+	if cleaned != p || cleaned == ".." || strings.HasPrefix(cleaned, ".."+string(filepath.Separator)) {
+		return fmt.Errorf("snapshot: unsafe file path %q in manifest", p)
+	}
+	return nil
+}
+
+// validateAddr rejects any chunk address that isn't a well-formed
+// BLAKE2b-256 hex digest, so a tampered manifest can't make Restore
+// pass an arbitrary backend-specific string (e.g. one that escapes
+// LocalBackend's directory via its unchecked filepath.Join) into
+// backend.Get.
+func validateAddr(addr string) ([]byte, error) {
+	if len(addr) != 2*blake2b.Size256 {
+		return nil, fmt.Errorf("snapshot: invalid chunk address %q in manifest", addr)
+	}
+	raw, err := hex.DecodeString(addr)
+	if err != nil {
+		return nil, fmt.Errorf("snapshot: invalid chunk address %q in manifest: %w", addr, err)
+	}
+	return raw, nil
+}
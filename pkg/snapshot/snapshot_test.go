@@ -0,0 +1,156 @@
+package snapshot
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeTestTree(t *testing.T, dir string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Join(dir, "sub"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	big := make([]byte, 40<<10)
+	if _, err := rand.Read(big); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "root.bin"), big, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "sub", "small.txt"), []byte("hello snapshot"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCreateRestoreRoundTrip(t *testing.T) {
+	src := t.TempDir()
+	writeTestTree(t, src)
+
+	backend, err := NewLocalBackend(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	master := make([]byte, 32)
+	if _, err := rand.Read(master); err != nil {
+		t.Fatal(err)
+	}
+
+	rootID, err := Create(src, backend, master, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dest := t.TempDir()
+	if err := Restore(rootID, dest, backend, master); err != nil {
+		t.Fatal(err)
+	}
+
+	want, err := os.ReadFile(filepath.Join(src, "root.bin"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := os.ReadFile(filepath.Join(dest, "root.bin"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Fatal("restored root.bin does not match source")
+	}
+
+	gotSmall, err := os.ReadFile(filepath.Join(dest, "sub", "small.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(gotSmall) != "hello snapshot" {
+		t.Fatalf("restored sub/small.txt = %q, want %q", gotSmall, "hello snapshot")
+	}
+}
+
+func TestRestoreRejectsTamperedManifest(t *testing.T) {
+	src := t.TempDir()
+	writeTestTree(t, src)
+
+	backendDir := t.TempDir()
+	backend, err := NewLocalBackend(backendDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	master := make([]byte, 32)
+	if _, err := rand.Read(master); err != nil {
+		t.Fatal(err)
+	}
+
+	rootID, err := Create(src, backend, master, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	manifestBytes, err := backend.Get(rootID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tampered := bytes.Replace(manifestBytes, []byte("root.bin"), []byte("rootXbin"), 1)
+	if bytes.Equal(tampered, manifestBytes) {
+		t.Fatal("test setup: tamper replace had no effect")
+	}
+	if err := backend.Put(rootID, tampered); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := Restore(rootID, t.TempDir(), backend, master); err == nil {
+		t.Fatal("expected Restore to reject a tampered manifest")
+	}
+}
+
+func TestRestoreRejectsPathTraversal(t *testing.T) {
+	backend, err := NewLocalBackend(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	master := make([]byte, 32)
+	if _, err := rand.Read(master); err != nil {
+		t.Fatal(err)
+	}
+
+	addr, ciphertext, ph, err := sealChunk(master, []byte("payload"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := backend.Put(addr, ciphertext); err != nil {
+		t.Fatal(err)
+	}
+
+	manifest := Manifest{Files: []FileEntry{{
+		Path:   "../escaped.bin",
+		Mode:   0o600,
+		Chunks: []ChunkRef{{Addr: addr, PlainHash: hex.EncodeToString(ph[:]), Length: len("payload")}},
+	}}}
+	manifestBytes, err := json.Marshal(manifest)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var leaf [32]byte
+	addrBytes, err := validateAddr(addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	copy(leaf[:], addrBytes)
+	root := merkleRoot([][32]byte{leaf})
+	rootID, err := snapshotID(master, root, manifestBytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := backend.Put(rootID, manifestBytes); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := Restore(rootID, t.TempDir(), backend, master); err == nil {
+		t.Fatal("expected Restore to reject a manifest with a path-traversal entry")
+	}
+}
@@ -0,0 +1,147 @@
+package snapshot
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// ErrWriteOnly is returned by a SneakernetBackend's read/enumerate/
+// delete methods: a sneakernet blob set is meant to be carried across
+// the air gap and consumed by importing it into a LocalBackend on the
+// other side, not read back in place.
+var ErrWriteOnly = errors.New("snapshot: backend is write-only")
+
+// blobIndexEntry records where one chunk landed within the sneakernet
+// blob set, so the receiving side can unpack it without re-chunking.
+type blobIndexEntry struct {
+	Blob   string `json:"blob"`
+	Offset int64  `json:"offset"`
+	Length int    `json:"length"`
+}
+
+// SneakernetBackend packs chunks into a numbered sequence of blob files
+// no larger than maxBlobBytes each, for physical transport across an
+// air gap. Call Close to flush the final blob and write the index.
+type SneakernetBackend struct {
+	dir          string
+	maxBlobBytes int64
+
+	index      map[string]blobIndexEntry
+	blobNum    int
+	blobFile   *os.File
+	blobOffset int64
+}
+
+// NewSneakernetBackend creates a new blob set under dir.
+func NewSneakernetBackend(dir string, maxBlobBytes int64) (*SneakernetBackend, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	return &SneakernetBackend{
+		dir:          dir,
+		maxBlobBytes: maxBlobBytes,
+		index:        make(map[string]blobIndexEntry),
+	}, nil
+}
+
+func (b *SneakernetBackend) blobName(n int) string {
+	return filepath.Join(b.dir, fmt.Sprintf("blob-%05d.bin", n))
+}
+
+func (b *SneakernetBackend) Put(id string, data []byte) error {
+	if b.blobFile == nil || b.blobOffset+int64(len(data)) > b.maxBlobBytes {
+		if err := b.rollBlob(); err != nil {
+			return err
+		}
+	}
+	n, err := b.blobFile.Write(data)
+	if err != nil {
+		return err
+	}
+	b.index[id] = blobIndexEntry{Blob: filepath.Base(b.blobFile.Name()), Offset: b.blobOffset, Length: n}
+	b.blobOffset += int64(n)
+	return nil
+}
+
+func (b *SneakernetBackend) rollBlob() error {
+	if b.blobFile != nil {
+		if err := b.blobFile.Close(); err != nil {
+			return err
+		}
+	}
+	b.blobNum++
+	f, err := os.Create(b.blobName(b.blobNum))
+	if err != nil {
+		return err
+	}
+	b.blobFile = f
+	b.blobOffset = 0
+	return nil
+}
+
+// Close flushes the open blob file and writes index.json alongside the
+// blob set, mapping every chunk id to its (blob, offset, length).
+func (b *SneakernetBackend) Close() error {
+	if b.blobFile != nil {
+		if err := b.blobFile.Close(); err != nil {
+			return err
+		}
+		b.blobFile = nil
+	}
+	data, err := json.MarshalIndent(b.index, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(b.dir, "index.json"), data, 0o600)
+}
+
+func (b *SneakernetBackend) Get(id string) ([]byte, error) { return nil, ErrWriteOnly }
+func (b *SneakernetBackend) Has(id string) (bool, error) {
+	_, ok := b.index[id]
+	return ok, nil
+}
+func (b *SneakernetBackend) List() ([]string, error) { return nil, ErrWriteOnly }
+func (b *SneakernetBackend) Delete(id string) error  { return ErrWriteOnly }
+
+// ImportSneakernet reads a blob set written by SneakernetBackend and
+// copies every chunk it contains into dst, for use once the physical
+// media has crossed the air gap.
+func ImportSneakernet(dir string, dst Backend) error {
+	raw, err := os.ReadFile(filepath.Join(dir, "index.json"))
+	if err != nil {
+		return err
+	}
+	var index map[string]blobIndexEntry
+	if err := json.Unmarshal(raw, &index); err != nil {
+		return err
+	}
+
+	blobs := make(map[string]*os.File)
+	defer func() {
+		for _, f := range blobs {
+			f.Close()
+		}
+	}()
+
+	for id, entry := range index {
+		f, ok := blobs[entry.Blob]
+		if !ok {
+			f, err = os.Open(filepath.Join(dir, entry.Blob))
+			if err != nil {
+				return err
+			}
+			blobs[entry.Blob] = f
+		}
+		buf := make([]byte, entry.Length)
+		if _, err := f.ReadAt(buf, entry.Offset); err != nil {
+			return err
+		}
+		if err := dst.Put(id, buf); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,191 @@
+// Package tui is a live terminal dashboard for concurrent subsystem
+// status, modeled on jotframe's concurrent frame model: each long-lived
+// task (vault rekey, bundle verify, snapshot create, policy compile)
+// owns a fixed-position Frame and updates it independently without
+// corrupting any other frame's output, with a scrolling log region
+// below. Updates are coalesced by a redraw scheduler at <= 30 Hz to
+// keep CPU low on constrained mini-OS hardware, and the whole dashboard
+// degrades to plain sequential line output when stdout is not a TTY or
+// when running under the air-gap batch runner.
+package tui
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+// redrawInterval caps the dashboard's redraw rate at 30 Hz.
+const redrawInterval = time.Second / 30
+
+// maxLogLines bounds the scrolling log region kept in memory; older
+// lines are dropped as new ones arrive.
+const maxLogLines = 20
+
+// Dashboard owns a set of fixed-position Frames plus a scrolling log
+// region and redraws them on a coalescing schedule.
+type Dashboard struct {
+	w   io.Writer
+	tty bool
+
+	mu       sync.Mutex
+	frames   []*Frame
+	logLines []string
+	dirty    bool
+	rendered bool
+	lastRows int
+
+	startOnce sync.Once
+	stopCh    chan struct{}
+}
+
+// NewDashboard creates a Dashboard writing to w. It auto-detects
+// whether w is a terminal; non-terminal writers (pipes, the air-gap
+// batch runner's captured output, redirected files) always get plain
+// sequential lines regardless of frame usage.
+func NewDashboard(w io.Writer) *Dashboard {
+	return &Dashboard{w: w, tty: isTTY(w)}
+}
+
+func isTTY(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
+
+// Default is the process-wide dashboard that cobra commands attach
+// frames to when run with --tui.
+var Default = NewDashboard(os.Stdout)
+
+// NewFrame creates a new fixed-position frame on the default dashboard.
+func NewFrame(title string) *Frame {
+	return Default.NewFrame(title)
+}
+
+// NewFrame creates a new fixed-position frame, appended below any
+// existing frames, and starts the dashboard's redraw loop if this is
+// its first frame.
+func (d *Dashboard) NewFrame(title string) *Frame {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	f := &Frame{dashboard: d, title: title}
+	d.frames = append(d.frames, f)
+	d.markDirtyLocked()
+
+	d.startOnce.Do(func() {
+		d.stopCh = make(chan struct{})
+		go d.redrawLoop()
+	})
+	return f
+}
+
+func (d *Dashboard) redrawLoop() {
+	ticker := time.NewTicker(redrawInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			d.mu.Lock()
+			if d.dirty && d.tty {
+				d.renderLocked()
+				d.dirty = false
+			}
+			d.mu.Unlock()
+		case <-d.stopCh:
+			return
+		}
+	}
+}
+
+// Log appends a line to the scrolling region below the frames.
+func (d *Dashboard) Log(line string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if !d.tty {
+		fmt.Fprintln(d.w, line)
+		return
+	}
+	d.logLines = append(d.logLines, line)
+	if len(d.logLines) > maxLogLines {
+		d.logLines = d.logLines[len(d.logLines)-maxLogLines:]
+	}
+	d.markDirtyLocked()
+}
+
+// onFrameChanged is called by Frame.Set/Progress. In plain (non-TTY)
+// mode it prints the frame's new state immediately, since there's no
+// cursor to reposition and no reason to coalesce. In TTY mode it just
+// flags the dashboard dirty; the redraw loop picks it up on its next
+// tick, coalescing any number of updates that land within one frame
+// interval into a single redraw.
+func (d *Dashboard) onFrameChanged(f *Frame) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if !d.tty {
+		fmt.Fprintln(d.w, f.headerLine())
+		for _, line := range f.snapshotLines() {
+			fmt.Fprintln(d.w, "  "+line)
+		}
+		return
+	}
+	d.markDirtyLocked()
+}
+
+func (d *Dashboard) markDirtyLocked() {
+	d.dirty = true
+}
+
+// renderLocked redraws every frame in its fixed position followed by
+// the scrolling log tail. Caller must hold d.mu. It moves the cursor
+// back up to the top of the dashboard's region before each redraw so
+// frames never bleed into each other or into the log region.
+func (d *Dashboard) renderLocked() {
+	var out []byte
+	if d.rendered {
+		out = append(out, []byte(fmt.Sprintf("\x1b[%dA", d.lastRows))...)
+	}
+
+	rows := 0
+	for _, f := range d.frames {
+		out = append(out, []byte("\x1b[2K"+f.headerLine()+"\n")...)
+		rows++
+		for _, line := range f.snapshotLines() {
+			out = append(out, []byte("\x1b[2K  "+line+"\n")...)
+			rows++
+		}
+	}
+	for _, line := range d.logLines {
+		out = append(out, []byte("\x1b[2K"+line+"\n")...)
+		rows++
+	}
+
+	d.lastRows = rows
+	d.rendered = true
+	d.w.Write(out)
+}
+
+// Stop halts the redraw loop. It is safe to call on a Dashboard that
+// was never started (no frame was ever created).
+func (d *Dashboard) Stop() {
+	d.mu.Lock()
+	stopCh := d.stopCh
+	d.mu.Unlock()
+	if stopCh != nil {
+		select {
+		case <-stopCh:
+		default:
+			close(stopCh)
+		}
+	}
+}
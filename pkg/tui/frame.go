@@ -0,0 +1,51 @@
+package tui
+
+import (
+	"fmt"
+	"sync"
+)
+
+// Frame is one long-lived task's fixed-position region within a
+// Dashboard — vault rekey, bundle verify, snapshot create, and policy
+// compile each own one, so their updates never interleave with or
+// overwrite each other's output.
+type Frame struct {
+	dashboard *Dashboard
+	title     string
+
+	mu         sync.Mutex
+	lines      []string
+	cur, total int
+}
+
+// Set replaces the frame's body lines.
+func (f *Frame) Set(lines []string) {
+	f.mu.Lock()
+	f.lines = append([]string(nil), lines...)
+	f.mu.Unlock()
+	f.dashboard.onFrameChanged(f)
+}
+
+// Progress records cur out of total units of work complete; it is
+// rendered as part of the frame's header line.
+func (f *Frame) Progress(cur, total int) {
+	f.mu.Lock()
+	f.cur, f.total = cur, total
+	f.mu.Unlock()
+	f.dashboard.onFrameChanged(f)
+}
+
+func (f *Frame) headerLine() string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.total > 0 {
+		return fmt.Sprintf("[%s] %d/%d", f.title, f.cur, f.total)
+	}
+	return fmt.Sprintf("[%s]", f.title)
+}
+
+func (f *Frame) snapshotLines() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]string(nil), f.lines...)
+}
@@ -0,0 +1,38 @@
+package vault
+
+import "crypto/aes"
+
+// aesCipher adapts a standard library AES-256 block cipher to the
+// cipherBlock interface used by the EME math in eme.go.
+type aesCipher struct {
+	block blockCipher
+}
+
+// blockCipher is satisfied by the cipher.Block that aes.NewCipher
+// returns; kept as an interface so tests
+// can substitute a fake without linking crypto/aes's internals.
+type blockCipher interface {
+	BlockSize() int
+	Encrypt(dst, src []byte)
+	Decrypt(dst, src []byte)
+}
+
+func newAESCipher(key []byte) (aesCipher, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return aesCipher{}, err
+	}
+	return aesCipher{block: block}, nil
+}
+
+func (c aesCipher) encryptBlock(in [blockSize]byte) [blockSize]byte {
+	var out [blockSize]byte
+	c.block.Encrypt(out[:], in[:])
+	return out
+}
+
+func (c aesCipher) decryptBlock(in [blockSize]byte) [blockSize]byte {
+	var out [blockSize]byte
+	c.block.Decrypt(out[:], in[:])
+	return out
+}
@@ -0,0 +1,109 @@
+package vault
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+// PageSize is the fixed DB page size this vault supports. It must match
+// sqlite's own page_size pragma (4096 is sqlite's modern default); EME
+// masks are derived per page so a mismatch would silently corrupt data
+// rather than merely failing to open.
+const PageSize = 4096
+
+const (
+	headerMagic   = "OIVAULT1"
+	headerSaltLen = 16
+	headerMACLen  = sha256.Size
+	// headerSize is the size of the unencrypted region at the start of
+	// the vault file; DB page 1 begins immediately after it. It is one
+	// PageSize so the on-disk page grid stays aligned.
+	headerSize = PageSize
+)
+
+// ErrTamperedHeader is returned by verifyHeaderMAC when the header MAC does
+// not verify, meaning either the passphrase is wrong or the header bytes
+// were modified on disk.
+var ErrTamperedHeader = errors.New("vault: header MAC mismatch (wrong passphrase or tampered file)")
+
+type header struct {
+	version byte
+	salt    [headerSaltLen]byte
+	kdf     kdfParams
+}
+
+// encode serializes the header and appends an HMAC-SHA256 MAC computed
+// over every preceding byte, keyed by headerKey. The MAC only attests
+// the header is untampered and was produced with the right passphrase;
+// it intentionally covers no page contents, so it leaks nothing about
+// the data even to a holder of headerKey.
+func (h header) encode(headerKey []byte) []byte {
+	buf := make([]byte, 0, headerSize)
+	buf = append(buf, headerMagic...)
+	buf = append(buf, h.version)
+	buf = append(buf, h.salt[:]...)
+	buf = binary.BigEndian.AppendUint32(buf, uint32(h.kdf.N))
+	buf = binary.BigEndian.AppendUint32(buf, uint32(h.kdf.R))
+	buf = binary.BigEndian.AppendUint32(buf, uint32(h.kdf.P))
+	buf = binary.BigEndian.AppendUint32(buf, uint32(h.kdf.KeyLen))
+
+	mac := hmac.New(sha256.New, headerKey)
+	mac.Write(buf)
+	buf = mac.Sum(buf)
+
+	out := make([]byte, headerSize)
+	copy(out, buf)
+	return out
+}
+
+// decodeHeader parses the unencrypted prefix of a vault file. It does
+// not verify the MAC, since headerKey is only known once kdf params
+// (read from this same struct) have been used to derive it — callers
+// must call verifyHeaderMAC afterwards.
+func decodeHeader(raw []byte) (header, []byte, error) {
+	if len(raw) < headerSize {
+		return header{}, nil, fmt.Errorf("vault: short header (%d bytes)", len(raw))
+	}
+	if !bytes.Equal(raw[:len(headerMagic)], []byte(headerMagic)) {
+		return header{}, nil, errors.New("vault: not an oi vault file (bad magic)")
+	}
+	off := len(headerMagic)
+	var h header
+	h.version = raw[off]
+	off++
+	copy(h.salt[:], raw[off:off+headerSaltLen])
+	off += headerSaltLen
+	h.kdf.N = int(binary.BigEndian.Uint32(raw[off : off+4]))
+	off += 4
+	h.kdf.R = int(binary.BigEndian.Uint32(raw[off : off+4]))
+	off += 4
+	h.kdf.P = int(binary.BigEndian.Uint32(raw[off : off+4]))
+	off += 4
+	h.kdf.KeyLen = int(binary.BigEndian.Uint32(raw[off : off+4]))
+	off += 4
+
+	signed := raw[:off]
+	mac := raw[off : off+headerMACLen]
+	return h, append(append([]byte{}, signed...), mac...), nil
+}
+
+// verifyHeaderMAC recomputes the MAC over the signed prefix returned by
+// decodeHeader and compares it in constant time against the MAC stored
+// in the file.
+func verifyHeaderMAC(signedAndMAC []byte, headerKey []byte) error {
+	signed := signedAndMAC[:len(signedAndMAC)-headerMACLen]
+	want := signedAndMAC[len(signedAndMAC)-headerMACLen:]
+
+	mac := hmac.New(sha256.New, headerKey)
+	mac.Write(signed)
+	got := mac.Sum(nil)
+
+	if !hmac.Equal(got, want) {
+		return ErrTamperedHeader
+	}
+	return nil
+}
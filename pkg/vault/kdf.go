@@ -0,0 +1,53 @@
+package vault
+
+import (
+	"crypto/sha256"
+	"io"
+
+	"golang.org/x/crypto/hkdf"
+	"golang.org/x/crypto/scrypt"
+)
+
+// kdfParams captures the scrypt cost parameters stored (in the clear) in
+// the vault header so an existing vault can always be reopened without
+// guessing how it was derived.
+type kdfParams struct {
+	N      int
+	R      int
+	P      int
+	KeyLen int
+}
+
+// defaultKDFParams are used by Init for new vaults and by Rekey for the
+// header it rewrites.
+var defaultKDFParams = kdfParams{N: 1 << 15, R: 8, P: 1, KeyLen: 32}
+
+// deriveMaster runs scrypt over the passphrase and the per-vault salt to
+// produce a single master secret, from which pageKey (AES-256 content
+// encryption) and headerKey (HMAC over the header) are split via HKDF so
+// a header-MAC forgery attempt gains no information about the page key.
+func deriveMaster(passphrase string, salt []byte, p kdfParams) (pageKey, headerKey []byte, err error) {
+	master, err := scrypt.Key([]byte(passphrase), salt, p.N, p.R, p.P, p.KeyLen)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer zero(master)
+
+	reader := hkdf.New(sha256.New, master, salt, []byte("oi-vault-v1"))
+
+	pageKey = make([]byte, 32)
+	if _, err := io.ReadFull(reader, pageKey); err != nil {
+		return nil, nil, err
+	}
+	headerKey = make([]byte, 32)
+	if _, err := io.ReadFull(reader, headerKey); err != nil {
+		return nil, nil, err
+	}
+	return pageKey, headerKey, nil
+}
+
+func zero(b []byte) {
+	for i := range b {
+		b[i] = 0
+	}
+}
@@ -0,0 +1,174 @@
+// Package vault provides a transparent encrypted-store mode for the
+// GORM/SQLite persistence layer. Every DB page is encrypted at rest with
+// AES-256 in an EME (ECB-Mix-ECB) construction, giving length-preserving,
+// deterministic-per-offset encryption with no ciphertext expansion — the
+// same property gocryptfs relies on for its block encryption. A master
+// key is derived from an operator passphrase via scrypt, and a small
+// unencrypted header stores the KDF parameters plus a MAC so a wrong
+// passphrase or a tampered file is detected before any page is decrypted.
+package vault
+
+import (
+	"crypto/rand"
+	"fmt"
+	"os"
+	"sync/atomic"
+
+	"github.com/psanford/sqlite3vfs"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+var vfsCounter atomic.Uint64
+
+// Init creates a new, empty vault file at path protected by passphrase.
+// It fails if path already exists.
+func Init(path, passphrase string) error {
+	if _, err := os.Stat(path); err == nil {
+		return fmt.Errorf("vault: %s already exists", path)
+	}
+
+	var salt [headerSaltLen]byte
+	if _, err := rand.Read(salt[:]); err != nil {
+		return err
+	}
+
+	_, headerKey, err := deriveMaster(passphrase, salt[:], defaultKDFParams)
+	if err != nil {
+		return err
+	}
+
+	h := header{version: 1, salt: salt, kdf: defaultKDFParams}
+	encoded := h.encode(headerKey)
+
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_EXCL, 0o600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if _, err := f.Write(encoded); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Open verifies passphrase against the vault's header and returns a
+// gorm.Dialector backed by a page-encrypting sqlite VFS. Callers pass
+// the dialector to gorm.Open as they would sqlite.Open's, once the error
+// is checked: gorm.Open(dialector, &gorm.Config{}).
+func Open(path, passphrase string) (gorm.Dialector, error) {
+	pc, _, err := openPageCipher(path, passphrase)
+	if err != nil {
+		return nil, err
+	}
+
+	name := fmt.Sprintf("oi-vault-%d", vfsCounter.Add(1))
+	if err := sqlite3vfs.RegisterVFS(name, &vfs{path: path, pc: pc}); err != nil {
+		return nil, fmt.Errorf("vault: registering vfs: %w", err)
+	}
+
+	dsn := fmt.Sprintf("file:vault.db?vfs=%s&_pragma=page_size(%d)", name, PageSize)
+	return sqlite.Open(dsn), nil
+}
+
+// Verify checks that passphrase unlocks the vault's header MAC without
+// opening a database connection. It returns ErrTamperedHeader if the
+// passphrase is wrong or the header was modified.
+func Verify(path, passphrase string) error {
+	_, _, err := openPageCipher(path, passphrase)
+	return err
+}
+
+// Rekey decrypts every existing page with oldPassphrase and re-encrypts
+// the vault under newPassphrase, replacing the header's salt and KDF
+// parameters in place. onProgress, if non-nil, is called after each
+// page is rewritten with the number of pages done and the total.
+func Rekey(path, oldPassphrase, newPassphrase string, onProgress func(done, total int)) error {
+	oldPC, _, err := openPageCipher(path, oldPassphrase)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(path, os.O_RDWR, 0o600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	fi, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	numPages := (fi.Size() - headerSize) / PageSize
+
+	var newSalt [headerSaltLen]byte
+	if _, err := rand.Read(newSalt[:]); err != nil {
+		return err
+	}
+	newPageKey, newHeaderKey, err := deriveMaster(newPassphrase, newSalt[:], defaultKDFParams)
+	if err != nil {
+		return err
+	}
+	newPC, err := newPageCipher(newPageKey)
+	if err != nil {
+		return err
+	}
+
+	buf := make([]byte, PageSize)
+	for i := int64(0); i < numPages; i++ {
+		if _, err := f.ReadAt(buf, headerSize+i*PageSize); err != nil {
+			return fmt.Errorf("vault: rekey reading page %d: %w", i, err)
+		}
+		plain := oldPC.decryptPage(uint64(i), buf)
+		cipher := newPC.encryptPage(uint64(i), plain)
+		if _, err := f.WriteAt(cipher, headerSize+i*PageSize); err != nil {
+			return fmt.Errorf("vault: rekey writing page %d: %w", i, err)
+		}
+		if onProgress != nil {
+			onProgress(int(i)+1, int(numPages))
+		}
+	}
+
+	newHeader := header{version: 1, salt: newSalt, kdf: defaultKDFParams}
+	if _, err := f.WriteAt(newHeader.encode(newHeaderKey), 0); err != nil {
+		return err
+	}
+	return f.Sync()
+}
+
+// openPageCipher reads and verifies a vault's header, returning the
+// derived page cipher alongside the parsed header. Open, Verify and
+// Rekey use only the page cipher (Rekey's page count math uses file size
+// directly).
+func openPageCipher(path, passphrase string) (pageCipher, header, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return pageCipher{}, header{}, err
+	}
+	defer f.Close()
+
+	raw := make([]byte, headerSize)
+	if _, err := f.ReadAt(raw, 0); err != nil {
+		return pageCipher{}, header{}, fmt.Errorf("vault: reading header: %w", err)
+	}
+
+	h, signedAndMAC, err := decodeHeader(raw)
+	if err != nil {
+		return pageCipher{}, header{}, err
+	}
+
+	pageKey, headerKey, err := deriveMaster(passphrase, h.salt[:], h.kdf)
+	if err != nil {
+		return pageCipher{}, header{}, err
+	}
+	if err := verifyHeaderMAC(signedAndMAC, headerKey); err != nil {
+		return pageCipher{}, header{}, err
+	}
+
+	pc, err := newPageCipher(pageKey)
+	if err != nil {
+		return pageCipher{}, header{}, err
+	}
+	return pc, h, nil
+}
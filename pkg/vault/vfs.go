@@ -0,0 +1,141 @@
+package vault
+
+import (
+	"os"
+	"sync"
+
+	"github.com/psanford/sqlite3vfs"
+)
+
+// vfs implements sqlite3vfs.VFS, transparently encrypting every DB page
+// written through it with the EME page cipher. Each opened vault gets
+// its own VFS instance, registered under a unique name so multiple
+// vaults can be open in the same process.
+type vfs struct {
+	path string
+	pc   pageCipher
+}
+
+func (v *vfs) Open(name string, flags sqlite3vfs.OpenFlag) (sqlite3vfs.File, sqlite3vfs.OpenFlag, error) {
+	f, err := os.OpenFile(v.path, os.O_RDWR|os.O_CREATE, 0o600)
+	if err != nil {
+		return nil, 0, err
+	}
+	return &vaultFile{f: f, pc: v.pc}, flags, nil
+}
+
+func (v *vfs) Delete(name string, dirSync bool) error {
+	return os.Remove(v.path)
+}
+
+func (v *vfs) Access(name string, flag sqlite3vfs.AccessFlag) (bool, error) {
+	_, err := os.Stat(v.path)
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func (v *vfs) FullPathname(name string) string {
+	return v.path
+}
+
+// vaultFile implements sqlite3vfs.File over an encrypted vault. DB page
+// N (sqlite's 1-based numbering) lives at byte offset
+// headerSize + (N-1)*PageSize in the underlying file.
+type vaultFile struct {
+	mu sync.Mutex
+	f  *os.File
+	pc pageCipher
+}
+
+func (vf *vaultFile) pageIndexForOffset(off int64) uint64 {
+	return uint64(off / PageSize)
+}
+
+func (vf *vaultFile) readPage(pageIndex uint64) ([]byte, error) {
+	cipherPage := make([]byte, PageSize)
+	n, err := vf.f.ReadAt(cipherPage, headerSize+int64(pageIndex)*PageSize)
+	if err != nil && n == 0 {
+		if err.Error() == "EOF" {
+			return make([]byte, PageSize), nil
+		}
+		return nil, err
+	}
+	if n < PageSize {
+		// Page has never been written; treat as all-zero plaintext.
+		return make([]byte, PageSize), nil
+	}
+	return vf.pc.decryptPage(pageIndex, cipherPage), nil
+}
+
+func (vf *vaultFile) writePage(pageIndex uint64, plaintext []byte) error {
+	ciphertext := vf.pc.encryptPage(pageIndex, plaintext)
+	_, err := vf.f.WriteAt(ciphertext, headerSize+int64(pageIndex)*PageSize)
+	return err
+}
+
+func (vf *vaultFile) ReadAt(p []byte, off int64) (int, error) {
+	vf.mu.Lock()
+	defer vf.mu.Unlock()
+
+	pageIndex := vf.pageIndexForOffset(off)
+	plain, err := vf.readPage(pageIndex)
+	if err != nil {
+		return 0, err
+	}
+	inPage := int(off % PageSize)
+	n := copy(p, plain[inPage:])
+	return n, nil
+}
+
+func (vf *vaultFile) WriteAt(p []byte, off int64) (int, error) {
+	vf.mu.Lock()
+	defer vf.mu.Unlock()
+
+	pageIndex := vf.pageIndexForOffset(off)
+	plain, err := vf.readPage(pageIndex)
+	if err != nil {
+		return 0, err
+	}
+	inPage := int(off % PageSize)
+	n := copy(plain[inPage:], p)
+	if err := vf.writePage(pageIndex, plain); err != nil {
+		return 0, err
+	}
+	return n, nil
+}
+
+func (vf *vaultFile) Truncate(size int64) error {
+	return vf.f.Truncate(headerSize + size)
+}
+
+func (vf *vaultFile) Sync(flag sqlite3vfs.SyncType) error {
+	return vf.f.Sync()
+}
+
+func (vf *vaultFile) FileSize() (int64, error) {
+	fi, err := vf.f.Stat()
+	if err != nil {
+		return 0, err
+	}
+	size := fi.Size() - headerSize
+	if size < 0 {
+		size = 0
+	}
+	return size, nil
+}
+
+func (vf *vaultFile) Lock(elock sqlite3vfs.LockType) error   { return nil }
+func (vf *vaultFile) Unlock(elock sqlite3vfs.LockType) error { return nil }
+func (vf *vaultFile) CheckReservedLock() (bool, error)       { return false, nil }
+func (vf *vaultFile) SectorSize() int64                      { return PageSize }
+func (vf *vaultFile) DeviceCharacteristics() sqlite3vfs.DeviceCharacteristic {
+	return 0
+}
+
+func (vf *vaultFile) Close() error {
+	vf.mu.Lock()
+	defer vf.mu.Unlock()
+	return vf.f.Close()
+}